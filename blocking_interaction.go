@@ -0,0 +1,133 @@
+package moka
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+
+// blockingInteraction wraps an interaction, blocking every matching call
+// before delegating to it. Context-aware blocking interactions stop blocking
+// when the first `context.Context` argument is done, returning its error.
+type blockingInteraction struct {
+	methodName   string
+	args         []interface{}
+	interaction  Interaction
+	block        func(done <-chan struct{}) bool
+	contextAware bool
+	returnTypes  []reflect.Type
+}
+
+func newBlockingInteraction(methodName string, args []interface{}, interaction Interaction, block func(done <-chan struct{}) bool, contextAware bool) *blockingInteraction {
+	return &blockingInteraction{
+		methodName:   methodName,
+		args:         args,
+		interaction:  interaction,
+		block:        block,
+		contextAware: contextAware,
+	}
+}
+
+func blockUntil(channel <-chan struct{}) func(done <-chan struct{}) bool {
+	return func(done <-chan struct{}) bool {
+		select {
+		case <-channel:
+			return true
+		case <-done:
+			return false
+		}
+	}
+}
+
+func delay(clock Clock, duration time.Duration) func(done <-chan struct{}) bool {
+	return func(done <-chan struct{}) bool {
+		select {
+		case <-clock.After(duration):
+			return true
+		case <-done:
+			return false
+		}
+	}
+}
+
+func (i *blockingInteraction) MethodName() string {
+	return i.methodName
+}
+
+func (i *blockingInteraction) literalArgs() ([]interface{}, bool) {
+	return i.args, i.args != nil
+}
+
+func (i *blockingInteraction) Call(methodName string, args []interface{}) ([]interface{}, bool, error) {
+	if i.methodName != methodName || (i.args != nil && !argsMatch(i.args, args)) {
+		return nil, false, nil
+	}
+
+	var ctx context.Context
+	var done <-chan struct{}
+	if i.contextAware {
+		ctx = contextArg(args)
+		if ctx != nil {
+			done = ctx.Done()
+		}
+	}
+
+	if !i.block(done) {
+		return errorReturnValues(i.returnTypes, ctx.Err()), true, nil
+	}
+
+	return i.interaction.Call(methodName, args)
+}
+
+func contextArg(args []interface{}) context.Context {
+	for _, arg := range args {
+		if ctx, isContext := arg.(context.Context); isContext {
+			return ctx
+		}
+	}
+
+	return nil
+}
+
+func (i *blockingInteraction) Verify() error {
+	return i.interaction.Verify()
+}
+
+func (i *blockingInteraction) String() string {
+	return fmt.Sprint(i.interaction)
+}
+
+// CheckType also records the return types of the method for context-aware
+// interactions, so that all non-error return values can be filled with zero
+// values when the context is done.
+func (i *blockingInteraction) CheckType(t reflect.Type) error {
+	err := i.interaction.CheckType(t)
+	if err != nil || !i.contextAware {
+		return err
+	}
+
+	method, err := lookupMethod(t, i.methodName)
+	if err != nil {
+		return err
+	}
+
+	takesContext := false
+	for _, argType := range methodArgTypes(method) {
+		takesContext = takesContext || argType.Implements(contextType)
+	}
+
+	if !takesContext {
+		return newInvalidInteractionError(method.Name, "method '%s.%s' doesn't take a context", typeName(t), method.Name)
+	}
+
+	returnTypes := methodReturnTypes(method)
+	if !returnsError(returnTypes) {
+		return newInvalidInteractionError(method.Name, "method '%s.%s' doesn't return an error", typeName(t), method.Name)
+	}
+
+	i.returnTypes = returnTypes
+	return nil
+}
@@ -0,0 +1,144 @@
+package moka
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("blockingInteraction", func() {
+	type callResult struct {
+		returnValues []interface{}
+		matched      bool
+		err          error
+	}
+
+	var unblock chan struct{}
+	var wrappedInteraction *fakeInteraction
+	var interaction Interaction
+
+	var callAsync = func(methodName string, args ...interface{}) chan callResult {
+		results := make(chan callResult, 1)
+		go func() {
+			returnValues, matched, err := interaction.Call(methodName, args)
+			results <- callResult{returnValues: returnValues, matched: matched, err: err}
+		}()
+		return results
+	}
+
+	BeforeEach(func() {
+		unblock = make(chan struct{})
+		wrappedInteraction = newFakeInteraction([]interface{}{"because", nil}, true, errors.New("verify"), nil)
+		interaction = newBlockingInteraction("RepeatQuestion", []interface{}{"why?"}, wrappedInteraction, blockUntil(unblock), false)
+	})
+
+	Describe("call", func() {
+		It("blocks until unblocked, then delegates to the wrapped interaction", func() {
+			results := callAsync("RepeatQuestion", "why?")
+
+			Consistently(results).ShouldNot(Receive())
+
+			close(unblock)
+
+			Eventually(results).Should(Receive(Equal(callResult{returnValues: []interface{}{"because", nil}, matched: true})))
+			Expect(wrappedInteraction.callCalled).To(BeTrue())
+		})
+
+		It("doesn't block on non-matching calls", func() {
+			returnValues, matched, err := interaction.Call("RepeatQuestion", []interface{}{"how?"})
+
+			Expect(returnValues).To(BeNil())
+			Expect(matched).To(BeFalse())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(wrappedInteraction.callCalled).To(BeFalse())
+		})
+
+		Context("when delaying", func() {
+			var clock *FakeClock
+
+			BeforeEach(func() {
+				clock = NewFakeClock()
+				interaction = newBlockingInteraction("RepeatQuestion", nil, wrappedInteraction, delay(clock, time.Minute), false)
+			})
+
+			It("waits for the clock to advance", func() {
+				results := callAsync("RepeatQuestion", "why?")
+
+				clock.WaitForTimers(1)
+				clock.Advance(30 * time.Second)
+				Consistently(results).ShouldNot(Receive())
+
+				clock.Advance(30 * time.Second)
+				Eventually(results).Should(Receive(Equal(callResult{returnValues: []interface{}{"because", nil}, matched: true})))
+			})
+		})
+
+		Context("when context-aware", func() {
+			var ctx context.Context
+			var cancel context.CancelFunc
+
+			BeforeEach(func() {
+				ctx, cancel = context.WithCancel(context.Background())
+				interaction = newBlockingInteraction("Fetch", nil, wrappedInteraction, blockUntil(unblock), true)
+			})
+
+			AfterEach(func() {
+				cancel()
+			})
+
+			It("returns the context error when the context is done", func() {
+				results := callAsync("Fetch", ctx, "key")
+
+				Consistently(results).ShouldNot(Receive())
+
+				cancel()
+
+				Eventually(results).Should(Receive(Equal(callResult{returnValues: []interface{}{context.Canceled}, matched: true})))
+				Expect(wrappedInteraction.callCalled).To(BeFalse())
+			})
+
+			It("fills the other return values with zero values on typed doubles", func() {
+				Expect(interaction.CheckType(reflect.TypeOf(myFetcher{}))).To(Succeed())
+
+				cancel()
+
+				Eventually(callAsync("Fetch", ctx, "key")).Should(Receive(Equal(callResult{returnValues: []interface{}{"", context.Canceled}, matched: true})))
+			})
+		})
+	})
+
+	Describe("verify", func() {
+		It("delegates to the wrapped interaction", func() {
+			Expect(interaction.Verify()).To(MatchError("verify"))
+		})
+	})
+
+	Describe("checkType", func() {
+		It("delegates to the wrapped interaction", func() {
+			wrappedInteraction.checkTypeError = errors.New("invalid")
+
+			Expect(interaction.CheckType(reflect.TypeOf(myFetcher{}))).To(MatchError("invalid"))
+		})
+
+		It("requires context-aware interactions to have a context argument and an error return value", func() {
+			Expect(newBlockingInteraction("RepeatQuestion", nil, wrappedInteraction, blockUntil(unblock), true).CheckType(reflect.TypeOf(myDeepThought{}))).To(
+				MatchError("Invalid interaction: method 'myDeepThought.RepeatQuestion' doesn't take a context"),
+			)
+			Expect(newBlockingInteraction("Cancel", nil, wrappedInteraction, blockUntil(unblock), true).CheckType(reflect.TypeOf(myFetcher{}))).To(
+				MatchError("Invalid interaction: method 'myFetcher.Cancel' doesn't return an error"),
+			)
+		})
+	})
+})
+
+type myFetcher struct{}
+
+func (f myFetcher) Fetch(ctx context.Context, key string) (string, error) {
+	return "", nil
+}
+
+func (f myFetcher) Cancel(ctx context.Context) {}
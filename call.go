@@ -0,0 +1,20 @@
+package moka
+
+// Call describes a method call received by a double.
+type Call struct {
+	// MethodName is the name of the called method.
+	MethodName string
+	// Args are the arguments the method has been called with.
+	Args []interface{}
+	// Index is the zero-based index of the call among the ones received by
+	// the same double. It is set for logged and notified calls, and for the
+	// calls passed to faults.
+	Index int
+	// MethodIndex is the zero-based index of the call among the ones to the
+	// same method received by the same double. It is set wherever `Index` is.
+	MethodIndex int
+	// InteractionIndex is the zero-based index of the call among the ones
+	// handled by the same interaction. It is only set for the calls passed to
+	// `AndReturnFunc`, which don't know about the double receiving them.
+	InteractionIndex int
+}
@@ -0,0 +1,79 @@
+package moka
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Captor is an argument matcher that matches any argument of type `T` and
+// records it, so that it can be inspected after the call. Captors can be used
+// in place of any argument passed to `With`.
+type Captor[T any] struct {
+	values []T
+	mutex  sync.Mutex
+}
+
+// NewCaptor instantiates a new `Captor` for arguments of type `T`.
+func NewCaptor[T any]() *Captor[T] {
+	return &Captor[T]{values: []T{}}
+}
+
+// Last returns the last captured value, or the zero value of `T` if no value
+// has been captured yet. When `T` is a func type, the returned value can be
+// invoked, e.g. to drive asynchronous completion handlers.
+func (c *Captor[T]) Last() T {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if len(c.values) == 0 {
+		var zero T
+		return zero
+	}
+
+	return c.values[len(c.values)-1]
+}
+
+// All returns all captured values, in the order they have been captured.
+func (c *Captor[T]) All() []T {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	values := make([]T, len(c.values))
+	copy(values, c.values)
+	return values
+}
+
+// GoString returns a representation of the captor suitable for interaction
+// descriptions.
+func (c *Captor[T]) GoString() string {
+	return fmt.Sprintf("Captor[%s]", typeString(c.matchedType()))
+}
+
+func (c *Captor[T]) matches(arg interface{}) bool {
+	_, ok := c.convert(arg)
+	return ok
+}
+
+func (c *Captor[T]) matched(arg interface{}) {
+	value, _ := c.convert(arg)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.values = append(c.values, value)
+}
+
+func (c *Captor[T]) matchedType() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+func (c *Captor[T]) convert(arg interface{}) (T, bool) {
+	if arg == nil {
+		var zero T
+		return zero, isNillable(c.matchedType())
+	}
+
+	value, ok := arg.(T)
+	return value, ok
+}
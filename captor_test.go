@@ -0,0 +1,71 @@
+package moka
+
+import (
+	"io"
+	"reflect"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Captor", func() {
+	var captor *Captor[string]
+
+	BeforeEach(func() {
+		captor = NewCaptor[string]()
+	})
+
+	Describe("matches", func() {
+		It("matches any argument of the captured type", func() {
+			Expect(captor.matches("life")).To(BeTrue())
+			Expect(captor.matches(42)).To(BeFalse())
+			Expect(captor.matches(nil)).To(BeFalse())
+		})
+
+		It("matches nil for nillable types", func() {
+			Expect(NewCaptor[[]string]().matches(nil)).To(BeTrue())
+			Expect(NewCaptor[io.Reader]().matches(nil)).To(BeTrue())
+		})
+
+		It("matches any argument implementing the captured interface", func() {
+			Expect(NewCaptor[io.Reader]().matches(strings.NewReader("life"))).To(BeTrue())
+			Expect(NewCaptor[io.Reader]().matches("life")).To(BeFalse())
+		})
+	})
+
+	Describe("Last and All", func() {
+		Context("when nothing has been captured", func() {
+			It("returns the zero value and an empty slice", func() {
+				Expect(captor.Last()).To(Equal(""))
+				Expect(captor.All()).To(BeEmpty())
+			})
+		})
+
+		Context("when some values have been captured", func() {
+			BeforeEach(func() {
+				captor.matched("life")
+				captor.matched("universe")
+				captor.matched("everything")
+			})
+
+			It("returns the captured values", func() {
+				Expect(captor.Last()).To(Equal("everything"))
+				Expect(captor.All()).To(Equal([]string{"life", "universe", "everything"}))
+			})
+		})
+	})
+
+	Describe("matchedType", func() {
+		It("returns the captured type", func() {
+			Expect(captor.matchedType()).To(Equal(reflect.TypeOf("")))
+			Expect(NewCaptor[io.Reader]().matchedType()).To(Equal(reflect.TypeOf((*io.Reader)(nil)).Elem()))
+		})
+	})
+
+	Describe("GoString", func() {
+		It("describes the captured type", func() {
+			Expect(formatMethodCall("UltimateQuestion", []interface{}{"life", captor})).To(Equal("UltimateQuestion(\"life\", Captor[string])"))
+		})
+	})
+})
@@ -0,0 +1,466 @@
+package moka
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+)
+
+// Cassette describes where and how recorded calls are stored.
+type Cassette struct {
+	// Path is the path of the cassette file.
+	Path string
+
+	// Codec serialises the cassette file and the values it contains. Defaults
+	// to `NewJSONCassetteCodec()`.
+	Codec CassetteCodec
+
+	// TypeCodecs convert values that can't be serialised by the codec as they
+	// are. Errors are always converted with `NewErrorTypeCodec()`.
+	TypeCodecs []TypeCodec
+
+	// Types lists values of the types that can be passed as interfaces, like
+	// `interface{}`, besides the predeclared ones. Those values are recorded
+	// with their dynamic type, which must be listed to replay them.
+	Types []interface{}
+}
+
+// CassetteCodec serialises cassettes and the values they contain.
+type CassetteCodec interface {
+	Marshal(value interface{}) ([]byte, error)
+	Unmarshal(data []byte, target interface{}) error
+}
+
+// TypeCodec converts values of the types it handles to and from a string
+// representation, for types that can't be serialised by a `CassetteCodec`.
+type TypeCodec interface {
+	Handles(t reflect.Type) bool
+	Encode(value interface{}) (string, error)
+	Decode(representation string, t reflect.Type) (interface{}, error)
+}
+
+// JSONCassetteCodec stores cassettes as indented JSON.
+type JSONCassetteCodec struct{}
+
+// NewJSONCassetteCodec instantiates a new `JSONCassetteCodec`.
+func NewJSONCassetteCodec() JSONCassetteCodec {
+	return JSONCassetteCodec{}
+}
+
+// Marshal returns the JSON encoding of the value.
+func (c JSONCassetteCodec) Marshal(value interface{}) ([]byte, error) {
+	return json.MarshalIndent(value, "", "  ")
+}
+
+// Unmarshal parses JSON data into the value pointed by target.
+func (c JSONCassetteCodec) Unmarshal(data []byte, target interface{}) error {
+	return json.Unmarshal(data, target)
+}
+
+// GobCassetteCodec stores cassettes with `encoding/gob`. Concrete types
+// stored in interface values must be registered with `gob.Register`.
+type GobCassetteCodec struct{}
+
+// NewGobCassetteCodec instantiates a new `GobCassetteCodec`.
+func NewGobCassetteCodec() GobCassetteCodec {
+	return GobCassetteCodec{}
+}
+
+// Marshal returns the gob encoding of the value.
+func (c GobCassetteCodec) Marshal(value interface{}) ([]byte, error) {
+	var buffer bytes.Buffer
+	err := gob.NewEncoder(&buffer).Encode(value)
+	return buffer.Bytes(), err
+}
+
+// Unmarshal parses gob data into the value pointed by target.
+func (c GobCassetteCodec) Unmarshal(data []byte, target interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(target)
+}
+
+// ErrorTypeCodec converts errors to their messages.
+type ErrorTypeCodec struct{}
+
+// NewErrorTypeCodec instantiates a new `ErrorTypeCodec`.
+func NewErrorTypeCodec() ErrorTypeCodec {
+	return ErrorTypeCodec{}
+}
+
+// Handles returns whether the type is `error`.
+func (c ErrorTypeCodec) Handles(t reflect.Type) bool {
+	return t == errorType
+}
+
+// Encode returns the message of the error.
+func (c ErrorTypeCodec) Encode(value interface{}) (string, error) {
+	return value.(error).Error(), nil
+}
+
+// Decode returns a new error with the specified message.
+func (c ErrorTypeCodec) Decode(representation string, t reflect.Type) (interface{}, error) {
+	return errors.New(representation), nil
+}
+
+// cassetteFile is the serialised form of a cassette.
+type cassetteFile struct {
+	Calls []cassetteCall `json:"calls"`
+}
+
+type cassetteCall struct {
+	MethodName   string          `json:"method"`
+	Args         []cassetteValue `json:"args"`
+	ReturnValues []cassetteValue `json:"returnValues"`
+}
+
+// cassetteValue is a serialised value. Values that can't be recorded, like
+// functions, channels and contexts, are matched by type when replayed. Values
+// passed as interfaces are recorded with the name of their dynamic type.
+type cassetteValue struct {
+	Data       json.RawMessage `json:"data,omitempty"`
+	Type       string          `json:"type,omitempty"`
+	Nil        bool            `json:"nil,omitempty"`
+	Unrecorded bool            `json:"unrecorded,omitempty"`
+}
+
+// NewStrictDoubleFromCassette instantiates a new `StrictDouble` with the type
+// of the specified value, allowing all calls recorded in a cassette. When the
+// same call has been recorded multiple times, the recorded return values are
+// returned in turn, repeating the last ones.
+func NewStrictDoubleFromCassette(value interface{}, cassette Cassette) (*StrictDouble, error) {
+	t := reflect.TypeOf(value)
+
+	recordedCalls, err := cassette.read(t)
+	if err != nil {
+		return nil, err
+	}
+
+	double := NewStrictDoubleWithTypeOf(value)
+	for _, group := range groupRecordedCalls(recordedCalls) {
+		returnValues := group.returnValues
+		err := double.AddInteraction(newReturnFuncInteraction(group.methodName, group.args, func(call Call) []interface{} {
+			if call.InteractionIndex >= len(returnValues) {
+				return returnValues[len(returnValues)-1]
+			}
+
+			return returnValues[call.InteractionIndex]
+		}))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return double, nil
+}
+
+type recordedCallGroup struct {
+	methodName   string
+	args         []interface{}
+	returnValues [][]interface{}
+}
+
+func groupRecordedCalls(recordedCalls []RecordedCall) []*recordedCallGroup {
+	groups := []*recordedCallGroup{}
+
+	for _, recordedCall := range recordedCalls {
+		var matchingGroup *recordedCallGroup
+		for _, group := range groups {
+			if group.methodName == recordedCall.MethodName && reflect.DeepEqual(group.args, recordedCall.Args) {
+				matchingGroup = group
+				break
+			}
+		}
+
+		if matchingGroup == nil {
+			matchingGroup = &recordedCallGroup{methodName: recordedCall.MethodName, args: recordedCall.Args}
+			groups = append(groups, matchingGroup)
+		}
+
+		matchingGroup.returnValues = append(matchingGroup.returnValues, recordedCall.ReturnValues)
+	}
+
+	return groups
+}
+
+func (c Cassette) codec() CassetteCodec {
+	if c.Codec == nil {
+		return NewJSONCassetteCodec()
+	}
+
+	return c.Codec
+}
+
+func (c Cassette) typeCodecs() []TypeCodec {
+	return append(append([]TypeCodec{}, c.TypeCodecs...), NewErrorTypeCodec())
+}
+
+// typeCodec returns the first type codec handling the type, or nil if there
+// is none.
+func (c Cassette) typeCodec(t reflect.Type) TypeCodec {
+	for _, typeCodec := range c.typeCodecs() {
+		if typeCodec.Handles(t) {
+			return typeCodec
+		}
+	}
+
+	return nil
+}
+
+// lookupType finds a type by name among the predeclared types and the ones
+// listed in `Types`.
+func (c Cassette) lookupType(name string) (reflect.Type, error) {
+	for _, value := range append(append([]interface{}{}, c.Types...), predeclaredTypeValues...) {
+		t := reflect.TypeOf(value)
+		if t.String() == name {
+			return t, nil
+		}
+	}
+
+	return nil, fmt.Errorf("type '%s' is not listed in the cassette types", name)
+}
+
+var predeclaredTypeValues = []interface{}{
+	false, "",
+	int(0), int8(0), int16(0), int32(0), int64(0),
+	uint(0), uint8(0), uint16(0), uint32(0), uint64(0), uintptr(0),
+	float32(0), float64(0),
+}
+
+func (c Cassette) write(t reflect.Type, recordedCalls []RecordedCall) error {
+	file, err := c.encodeCalls(t, recordedCalls)
+	if err != nil {
+		return err
+	}
+
+	return c.writeFile(file)
+}
+
+func (c Cassette) writeFile(file interface{}) error {
+	data, err := c.codec().Marshal(file)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(c.Path, data, 0644)
+}
+
+func (c Cassette) encodeCalls(t reflect.Type, recordedCalls []RecordedCall) (cassetteFile, error) {
+	file := cassetteFile{Calls: []cassetteCall{}}
+
+	for _, recordedCall := range recordedCalls {
+		method, err := lookupMethod(t, recordedCall.MethodName)
+		if err != nil {
+			return cassetteFile{}, err
+		}
+
+		args, err := c.encodeValues(recordedCall.Args, methodArgTypes(method))
+		if err != nil {
+			return cassetteFile{}, fmt.Errorf("Cannot record %s: %s", formatMethodCall(recordedCall.MethodName, recordedCall.Args), err)
+		}
+
+		returnValues, err := c.encodeValues(recordedCall.ReturnValues, methodReturnTypes(method))
+		if err != nil {
+			return cassetteFile{}, fmt.Errorf("Cannot record %s: %s", formatMethodCall(recordedCall.MethodName, recordedCall.Args), err)
+		}
+
+		file.Calls = append(file.Calls, cassetteCall{MethodName: recordedCall.MethodName, Args: args, ReturnValues: returnValues})
+	}
+
+	return file, nil
+}
+
+func (c Cassette) read(t reflect.Type) ([]RecordedCall, error) {
+	var file cassetteFile
+	err := c.readFile(&file)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.decodeCalls(t, file, true)
+}
+
+func (c Cassette) readFile(file interface{}) error {
+	data, err := os.ReadFile(c.Path)
+	if err != nil {
+		return err
+	}
+
+	return c.codec().Unmarshal(data, file)
+}
+
+// decodeCalls decodes the calls in a cassette file. When matchUnrecorded is
+// true, unrecorded arguments are decoded as matchers for their type.
+func (c Cassette) decodeCalls(t reflect.Type, file cassetteFile, matchUnrecorded bool) ([]RecordedCall, error) {
+	recordedCalls := []RecordedCall{}
+	for _, call := range file.Calls {
+		method, err := lookupMethod(t, call.MethodName)
+		if err != nil {
+			return nil, err
+		}
+
+		args, err := c.decodeValues(call.Args, methodArgTypes(method), matchUnrecorded)
+		if err != nil {
+			return nil, fmt.Errorf("Cannot replay a call to %s: %s", call.MethodName, err)
+		}
+
+		returnValues, err := c.decodeValues(call.ReturnValues, methodReturnTypes(method), false)
+		if err != nil {
+			return nil, fmt.Errorf("Cannot replay a call to %s: %s", call.MethodName, err)
+		}
+
+		recordedCalls = append(recordedCalls, RecordedCall{MethodName: call.MethodName, Args: args, ReturnValues: returnValues})
+	}
+
+	return recordedCalls, nil
+}
+
+func (c Cassette) encodeValues(values []interface{}, types []reflect.Type) ([]cassetteValue, error) {
+	if len(values) != len(types) {
+		return nil, fmt.Errorf("%d values given, %d expected", len(values), len(types))
+	}
+
+	encodedValues := []cassetteValue{}
+	for i, value := range values {
+		encodedValue, err := c.encodeValue(value, types[i])
+		if err != nil {
+			return nil, fmt.Errorf("value %d: %s", i+1, err)
+		}
+
+		encodedValues = append(encodedValues, encodedValue)
+	}
+
+	return encodedValues, nil
+}
+
+func (c Cassette) encodeValue(value interface{}, t reflect.Type) (cassetteValue, error) {
+	if isUnrecordable(t) {
+		return cassetteValue{Unrecorded: true}, nil
+	}
+
+	if value == nil {
+		return cassetteValue{Nil: true}, nil
+	}
+
+	encodedValue := cassetteValue{}
+	if t.Kind() == reflect.Interface && c.typeCodec(t) == nil {
+		t = reflect.TypeOf(value)
+		if isUnrecordable(t) {
+			return cassetteValue{Unrecorded: true}, nil
+		}
+
+		_, err := c.lookupType(t.String())
+		if err != nil {
+			return cassetteValue{}, err
+		}
+
+		encodedValue.Type = t.String()
+	}
+
+	typeCodec := c.typeCodec(t)
+	if typeCodec != nil {
+		representation, err := typeCodec.Encode(value)
+		if err != nil {
+			return cassetteValue{}, err
+		}
+
+		encodedValue.Data, err = c.codec().Marshal(representation)
+		return encodedValue, err
+	}
+
+	typedValue := reflect.New(t)
+	typedValue.Elem().Set(reflect.ValueOf(value))
+
+	var err error
+	encodedValue.Data, err = c.codec().Marshal(typedValue.Interface())
+	return encodedValue, err
+}
+
+func (c Cassette) decodeValues(encodedValues []cassetteValue, types []reflect.Type, matchUnrecorded bool) ([]interface{}, error) {
+	if len(encodedValues) != len(types) {
+		return nil, fmt.Errorf("%d values recorded, %d expected", len(encodedValues), len(types))
+	}
+
+	values := []interface{}{}
+	for i, encodedValue := range encodedValues {
+		value, err := c.decodeValue(encodedValue, types[i], matchUnrecorded)
+		if err != nil {
+			return nil, fmt.Errorf("value %d: %s", i+1, err)
+		}
+
+		values = append(values, value)
+	}
+
+	return values, nil
+}
+
+func (c Cassette) decodeValue(encodedValue cassetteValue, t reflect.Type, matchUnrecorded bool) (interface{}, error) {
+	if encodedValue.Unrecorded && matchUnrecorded {
+		return anyOfType{t: t}, nil
+	}
+
+	if encodedValue.Unrecorded && t == contextType {
+		return context.Background(), nil
+	}
+
+	if encodedValue.Unrecorded || encodedValue.Nil {
+		return reflect.Zero(t).Interface(), nil
+	}
+
+	if encodedValue.Type != "" {
+		var err error
+		t, err = c.lookupType(encodedValue.Type)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	typeCodec := c.typeCodec(t)
+	if typeCodec != nil {
+		var representation string
+		err := c.codec().Unmarshal(encodedValue.Data, &representation)
+		if err != nil {
+			return nil, err
+		}
+
+		return typeCodec.Decode(representation, t)
+	}
+
+	typedValue := reflect.New(t)
+	err := c.codec().Unmarshal(encodedValue.Data, typedValue.Interface())
+	if err != nil {
+		return nil, err
+	}
+
+	return typedValue.Elem().Interface(), nil
+}
+
+func isUnrecordable(t reflect.Type) bool {
+	return t.Kind() == reflect.Func ||
+		t.Kind() == reflect.Chan ||
+		t.Kind() == reflect.UnsafePointer ||
+		t.Implements(contextType)
+}
+
+// anyOfType is an argument matcher matching any argument assignable to a
+// type.
+type anyOfType struct {
+	t reflect.Type
+}
+
+func (m anyOfType) GoString() string {
+	return fmt.Sprintf("Any[%s]", typeString(m.t))
+}
+
+func (m anyOfType) matches(arg interface{}) bool {
+	return assignable(reflect.TypeOf(arg), m.t)
+}
+
+func (m anyOfType) matched(arg interface{}) {}
+
+func (m anyOfType) matchedType() reflect.Type {
+	return m.t
+}
@@ -0,0 +1,206 @@
+package moka
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type upperCaseTypeCodec struct{}
+
+func (c upperCaseTypeCodec) Handles(t reflect.Type) bool {
+	return t == reflect.TypeOf("")
+}
+
+func (c upperCaseTypeCodec) Encode(value interface{}) (string, error) {
+	return strings.ToUpper(value.(string)), nil
+}
+
+func (c upperCaseTypeCodec) Decode(representation string, t reflect.Type) (interface{}, error) {
+	return strings.ToLower(representation), nil
+}
+
+type cassettePoint struct {
+	X, Y int
+}
+
+var _ = Describe("Cassette", func() {
+	var dir string
+	var cassette Cassette
+	var collaborator CollaboratorDouble
+
+	record := func() {
+		recorder := NewRecorder(&realCollaborator{})
+		recordingCollaborator := CollaboratorDouble{Double: recorder}
+
+		recordingCollaborator.Query("arg")
+		recordingCollaborator.Command("arg")
+		recordingCollaborator.Command("arg")
+		recordingCollaborator.Command("")
+		recordingCollaborator.VariadicQuery("a", "b")
+		recordingCollaborator.Fetch(context.Background(), "key")
+
+		Expect(recorder.Save(cassette)).To(Succeed())
+	}
+
+	replay := func() {
+		double, err := NewStrictDoubleFromCassette(CollaboratorDouble{}, cassette)
+		Expect(err).NotTo(HaveOccurred())
+
+		collaborator = CollaboratorDouble{Double: double}
+	}
+
+	itReplaysTheRecordedCalls := func() {
+		It("replays the recorded calls", func() {
+			record()
+			replay()
+
+			Expect(collaborator.Query("arg")).To(Equal("ARG"))
+			Expect(collaborator.VariadicQuery("a", "b")).To(Equal("a,b"))
+
+			By("returning the results of repeated calls in turn", func() {
+				Expect(collaborator.Command("arg")).To(Equal("arg"))
+				Expect(collaborator.Command("arg")).To(Equal("argarg"))
+				Expect(collaborator.Command("arg")).To(Equal("argarg"))
+			})
+
+			By("replaying errors", func() {
+				_, err := collaborator.Command("")
+				Expect(err).To(MatchError("empty command"))
+			})
+
+			By("matching contexts by type", func() {
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+
+				Expect(collaborator.Fetch(ctx, "key")).To(Equal("value of key"))
+			})
+
+			Expect(testFailHandlerInvoked).To(BeFalse(), testFailMessage)
+
+			By("not allowing calls that haven't been recorded", func() {
+				collaborator.Query("other arg")
+
+				Expect(testFailHandlerInvoked).To(BeTrue())
+				Expect(testFailMessage).To(Equal("Unexpected interaction: Query(\"other arg\")"))
+			})
+		})
+	}
+
+	BeforeEach(func() {
+		resetTestFail()
+		RegisterDoublesFailHandler(testFailHandler)
+
+		var err error
+		dir, err = os.MkdirTemp("", "moka-cassette")
+		Expect(err).NotTo(HaveOccurred())
+
+		cassette = Cassette{Path: filepath.Join(dir, "cassette")}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	Context("with the default JSON codec", func() {
+		itReplaysTheRecordedCalls()
+
+		It("writes a readable JSON file", func() {
+			record()
+
+			data, err := os.ReadFile(cassette.Path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(ContainSubstring(`"method": "Query"`))
+		})
+	})
+
+	Context("with the gob codec", func() {
+		BeforeEach(func() {
+			cassette.Codec = NewGobCassetteCodec()
+		})
+
+		itReplaysTheRecordedCalls()
+	})
+
+	Context("with values passed as interfaces", func() {
+		BeforeEach(func() {
+			cassette.Types = []interface{}{cassettePoint{}}
+		})
+
+		recordDecodes := func() {
+			recorder := NewRecorder(&realCollaborator{})
+			recordingCollaborator := CollaboratorDouble{Double: recorder}
+
+			recordingCollaborator.Decode(42)
+			recordingCollaborator.Decode(cassettePoint{X: 1, Y: 2})
+
+			Expect(recorder.Save(cassette)).To(Succeed())
+		}
+
+		It("replays them with their dynamic type", func() {
+			recordDecodes()
+			replay()
+
+			Expect(collaborator.Decode(42)).To(Succeed())
+			Expect(collaborator.Decode(cassettePoint{X: 1, Y: 2})).To(Succeed())
+			Expect(testFailHandlerInvoked).To(BeFalse(), testFailMessage)
+		})
+
+		It("replays them with the gob codec", func() {
+			cassette.Codec = NewGobCassetteCodec()
+			recordDecodes()
+			replay()
+
+			Expect(collaborator.Decode(42)).To(Succeed())
+			Expect(collaborator.Decode(cassettePoint{X: 1, Y: 2})).To(Succeed())
+			Expect(testFailHandlerInvoked).To(BeFalse(), testFailMessage)
+		})
+
+		It("doesn't record values of types that aren't listed", func() {
+			recorder := NewRecorder(&realCollaborator{})
+			CollaboratorDouble{Double: recorder}.Decode([]string{"a"})
+
+			Expect(recorder.Save(cassette)).To(MatchError("Cannot record Decode([]string{\"a\"}): value 1: type '[]string' is not listed in the cassette types"))
+		})
+	})
+
+	Context("with custom type codecs", func() {
+		BeforeEach(func() {
+			cassette.TypeCodecs = []TypeCodec{upperCaseTypeCodec{}}
+		})
+
+		It("uses them to encode and decode values", func() {
+			record()
+
+			data, err := os.ReadFile(cassette.Path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(ContainSubstring(`"data": "KEY"`))
+
+			replay()
+			Expect(collaborator.Query("arg")).To(Equal("arg"))
+		})
+	})
+
+	Context("when the cassette doesn't exist", func() {
+		It("returns an error", func() {
+			_, err := NewStrictDoubleFromCassette(CollaboratorDouble{}, cassette)
+
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("when the cassette doesn't match the type", func() {
+		It("returns an error", func() {
+			record()
+
+			_, err := NewStrictDoubleFromCassette(myDeepThought{}, cassette)
+
+			Expect(err).To(MatchError("Invalid interaction: type 'myDeepThought' has no method 'Query'"))
+		})
+	})
+})
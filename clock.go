@@ -0,0 +1,104 @@
+package moka
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock is the source of time used by Moka doubles for delayed interactions.
+type Clock interface {
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (c realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+var globalClock Clock = realClock{}
+
+// RegisterDoublesClock registers a clock to be used by newly configured
+// delayed interactions. Registering `nil` restores the real clock.
+func RegisterDoublesClock(clock Clock) {
+	if clock == nil {
+		clock = realClock{}
+	}
+
+	globalClock = clock
+}
+
+// FakeClock is a `Clock` whose time only moves forward when `Advance` is
+// called, allowing delayed interactions to be tested quickly and
+// deterministically.
+type FakeClock struct {
+	now    time.Time
+	timers []fakeTimer
+	mutex  sync.Mutex
+	cond   *sync.Cond
+}
+
+type fakeTimer struct {
+	deadline time.Time
+	channel  chan time.Time
+}
+
+// NewFakeClock instantiates a new `FakeClock`.
+func NewFakeClock() *FakeClock {
+	clock := &FakeClock{now: time.Unix(0, 0)}
+	clock.cond = sync.NewCond(&clock.mutex)
+	return clock
+}
+
+// After returns a channel that will receive the current time once the clock
+// has been advanced by at least `d`.
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	channel := make(chan time.Time, 1)
+	if d <= 0 {
+		channel <- c.now
+		return channel
+	}
+
+	c.timers = append(c.timers, fakeTimer{deadline: c.now.Add(d), channel: channel})
+	c.cond.Broadcast()
+	return channel
+}
+
+// Advance moves the clock forward by `d`, firing all timers expiring in the
+// meantime.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.now = c.now.Add(d)
+
+	sort.SliceStable(c.timers, func(i, j int) bool {
+		return c.timers[i].deadline.Before(c.timers[j].deadline)
+	})
+
+	pendingTimers := []fakeTimer{}
+	for _, timer := range c.timers {
+		if timer.deadline.After(c.now) {
+			pendingTimers = append(pendingTimers, timer)
+		} else {
+			timer.channel <- c.now
+		}
+	}
+	c.timers = pendingTimers
+}
+
+// WaitForTimers blocks until at least `n` timers are waiting for the clock to
+// be advanced. Use it to make sure the code under test has reached a delayed
+// interaction before calling `Advance`.
+func (c *FakeClock) WaitForTimers(n int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for len(c.timers) < n {
+		c.cond.Wait()
+	}
+}
@@ -0,0 +1,48 @@
+package moka
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FakeClock", func() {
+	var clock *FakeClock
+
+	BeforeEach(func() {
+		clock = NewFakeClock()
+	})
+
+	Describe("After", func() {
+		It("fires only when the clock is advanced past the duration", func() {
+			channel := clock.After(time.Minute)
+
+			clock.Advance(59 * time.Second)
+			Consistently(channel).ShouldNot(Receive())
+
+			clock.Advance(time.Second)
+			Eventually(channel).Should(Receive(Equal(time.Unix(60, 0))))
+		})
+
+		It("fires immediately for non-positive durations", func() {
+			Eventually(clock.After(0)).Should(Receive())
+		})
+	})
+
+	Describe("WaitForTimers", func() {
+		It("blocks until enough timers are waiting", func() {
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				clock.WaitForTimers(2)
+			}()
+
+			clock.After(time.Second)
+			Consistently(done).ShouldNot(BeClosed())
+
+			clock.After(time.Second)
+			Eventually(done).Should(BeClosed())
+		})
+	})
+})
@@ -0,0 +1,222 @@
+package moka
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Contract collects the calls stubbed on typed doubles, i.e. the interactions
+// configured with literal arguments and return values, so that they can be
+// saved and verified against real implementations with `VerifyContract`.
+type Contract struct {
+	calls map[string][]RecordedCall
+	types map[string]reflect.Type
+	mutex sync.Mutex
+}
+
+// stubbedInteraction is implemented by interactions returning fixed values
+// for calls with literal arguments.
+type stubbedInteraction interface {
+	stubbedCall() (RecordedCall, bool)
+}
+
+var globalContract *Contract
+
+// RegisterDoublesContract registers a contract collecting the calls stubbed
+// on all typed doubles from now on. Registering `nil` stops collecting calls.
+func RegisterDoublesContract(contract *Contract) {
+	globalContract = contract
+}
+
+// NewContract instantiates a new, empty `Contract`.
+func NewContract() *Contract {
+	return &Contract{calls: map[string][]RecordedCall{}, types: map[string]reflect.Type{}}
+}
+
+func (c *Contract) record(t reflect.Type, interaction Interaction) {
+	stubbedInteraction, isStubbed := unwrapInteraction(interaction).(stubbedInteraction)
+	if !isStubbed {
+		return
+	}
+
+	call, ok := stubbedInteraction.stubbedCall()
+	if !ok {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	name := typeString(t)
+	for _, recordedCall := range c.calls[name] {
+		if reflect.DeepEqual(recordedCall, call) {
+			return
+		}
+	}
+
+	c.calls[name] = append(c.calls[name], call)
+	c.types[name] = t
+}
+
+// Calls returns the calls stubbed on doubles of the same type as the
+// specified double.
+func (c *Contract) Calls(double interface{}) []RecordedCall {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return append([]RecordedCall{}, c.calls[typeString(reflect.TypeOf(double))]...)
+}
+
+// Save writes the contract to a file, using the codecs of the cassette.
+func (c *Contract) Save(cassette Cassette) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	file := contractFile{Doubles: map[string]cassetteFile{}}
+	for name, calls := range c.calls {
+		doubleFile, err := cassette.encodeCalls(c.types[name], calls)
+		if err != nil {
+			return err
+		}
+
+		file.Doubles[name] = doubleFile
+	}
+
+	return cassette.writeFile(file)
+}
+
+type contractFile struct {
+	Doubles map[string]cassetteFile `json:"doubles"`
+}
+
+// ContractMismatch describes a stubbed call whose return values disagree
+// with the ones returned by the real implementation.
+type ContractMismatch struct {
+	MethodName          string
+	Args                []interface{}
+	StubbedReturnValues []interface{}
+	ActualReturnValues  []interface{}
+
+	// Err is set when the real implementation can't be called, or panics.
+	Err error
+}
+
+func (m ContractMismatch) String() string {
+	if m.Err != nil {
+		return fmt.Sprintf("%s: stubbed to return %s, failed with %s", formatMethodCall(m.MethodName, m.Args), formatValues(m.StubbedReturnValues), m.Err)
+	}
+
+	return fmt.Sprintf(
+		"%s: stubbed to return %s, actually returned %s",
+		formatMethodCall(m.MethodName, m.Args),
+		formatValues(m.StubbedReturnValues),
+		formatValues(m.ActualReturnValues),
+	)
+}
+
+// VerifyContract replays the calls stubbed on doubles of the same type as the
+// specified double, read from a contract file, against a real implementation.
+// It returns all calls for which the real implementation returns different
+// values, compared after serialising them with the codecs of the cassette.
+// Unrecorded arguments are replaced by zero values, or
+// `context.Background()` for contexts.
+func VerifyContract(cassette Cassette, double interface{}, real interface{}) ([]ContractMismatch, error) {
+	var file contractFile
+	err := cassette.readFile(&file)
+	if err != nil {
+		return nil, err
+	}
+
+	realValue := reflect.ValueOf(real)
+	calls, err := cassette.decodeCalls(realValue.Type(), file.Doubles[typeString(reflect.TypeOf(double))], false)
+	if err != nil {
+		return nil, err
+	}
+
+	mismatches := []ContractMismatch{}
+	for _, call := range calls {
+		method := methodValue(realValue, call.MethodName)
+		if !method.IsValid() {
+			mismatches = append(mismatches, ContractMismatch{
+				MethodName:          call.MethodName,
+				Args:                call.Args,
+				StubbedReturnValues: call.ReturnValues,
+				Err:                 fmt.Errorf("'%s' has no method '%s'", typeName(realValue.Type()), call.MethodName),
+			})
+			continue
+		}
+
+		returnTypes := methodReturnTypes(reflect.Method{Type: method.Type()})
+
+		actualReturnValues, err := callRecovering(nil, call.MethodName, call.Args, func() []interface{} {
+			return callValue(method, interfacesToValues(call.Args, method.Type()))
+		})
+		if err != nil {
+			mismatches = append(mismatches, ContractMismatch{MethodName: call.MethodName, Args: call.Args, StubbedReturnValues: call.ReturnValues, Err: err})
+			continue
+		}
+
+		normalisedReturnValues, err := cassette.normalise(actualReturnValues, returnTypes)
+		if err != nil {
+			return nil, err
+		}
+
+		if !reflect.DeepEqual(normalisedReturnValues, call.ReturnValues) {
+			mismatches = append(mismatches, ContractMismatch{
+				MethodName:          call.MethodName,
+				Args:                call.Args,
+				StubbedReturnValues: call.ReturnValues,
+				ActualReturnValues:  actualReturnValues,
+			})
+		}
+	}
+
+	return mismatches, nil
+}
+
+// normalise serialises and deserialises values, so that they can be compared
+// with values read from a file.
+func (c Cassette) normalise(values []interface{}, types []reflect.Type) ([]interface{}, error) {
+	encodedValues, err := c.encodeValues(values, types)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.decodeValues(encodedValues, types, false)
+}
+
+// methodValue returns the method with the specified name of a value. Function
+// values have a single method, named `FuncMethodName`, while the function
+// fields of structs are treated as methods.
+func methodValue(value reflect.Value, methodName string) reflect.Value {
+	if value.Kind() == reflect.Func && methodName == FuncMethodName {
+		return value
+	}
+
+	method := value.MethodByName(methodName)
+	if method.IsValid() {
+		return method
+	}
+
+	structValue := reflect.Indirect(value)
+	if structValue.Kind() == reflect.Struct {
+		field, fieldExists := structValue.Type().FieldByName(methodName)
+		if fieldExists && isFuncField(field) && !structValue.FieldByIndex(field.Index).IsNil() {
+			return structValue.FieldByIndex(field.Index)
+		}
+	}
+
+	return reflect.Value{}
+}
+
+// callValue calls a function value. For variadic functions, a last argument
+// of the type of the variadic slice is passed as the variadic slice.
+func callValue(function reflect.Value, args []reflect.Value) []interface{} {
+	functionType := function.Type()
+	if functionType.IsVariadic() && len(args) == functionType.NumIn() && args[len(args)-1].Type().AssignableTo(functionType.In(len(args)-1)) {
+		return valuesToInterfaces(function.CallSlice(args))
+	}
+
+	return valuesToInterfaces(function.Call(args))
+}
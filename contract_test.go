@@ -0,0 +1,92 @@
+package moka
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Contract", func() {
+	var contract *Contract
+	var collaborator CollaboratorDouble
+
+	BeforeEach(func() {
+		resetTestFail()
+		RegisterDoublesFailHandler(testFailHandler)
+
+		contract = NewContract()
+		RegisterDoublesContract(contract)
+
+		collaborator = NewCollaboratorDouble()
+	})
+
+	AfterEach(func() {
+		RegisterDoublesContract(nil)
+	})
+
+	It("collects the calls stubbed on typed doubles", func() {
+		AllowDouble(collaborator).To(ReceiveCallTo("Query").With("arg").AndReturn("ARG"))
+		ExpectDouble(collaborator).To(ReceiveCallTo("Command").With("").AndReturnError(errors.New("empty command")))
+		AllowDouble(collaborator).To(ReceiveCallTo("Query").With("arg").AndReturn("ARG"))
+
+		Expect(contract.Calls(CollaboratorDouble{})).To(Equal([]RecordedCall{
+			{MethodName: "Query", Args: []interface{}{"arg"}, ReturnValues: []interface{}{"ARG"}},
+			{MethodName: "Command", Args: []interface{}{""}, ReturnValues: []interface{}{"", errors.New("empty command")}},
+		}))
+	})
+
+	It("ignores interactions without literal arguments and return values", func() {
+		AllowDouble(collaborator).To(ReceiveCallTo("Query").AndReturn("ARG"))
+		AllowDouble(collaborator).To(ReceiveCallTo("Query").With(NewCaptor[string]()).AndReturn("ARG"))
+		AllowDouble(collaborator).To(ReceiveCallTo("Query").AndDo(func(arg string) string { return arg }))
+		AllowDouble(NewStrictDouble()).To(ReceiveCallTo("Query").With("arg").AndReturn("ARG"))
+
+		Expect(contract.Calls(CollaboratorDouble{})).To(BeEmpty())
+	})
+
+	Context("when saved", func() {
+		var dir string
+		var cassette Cassette
+
+		BeforeEach(func() {
+			var err error
+			dir, err = os.MkdirTemp("", "moka-contract")
+			Expect(err).NotTo(HaveOccurred())
+
+			cassette = Cassette{Path: filepath.Join(dir, "contract.json")}
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(dir)
+		})
+
+		It("can be verified against a real implementation", func() {
+			AllowDouble(collaborator).To(ReceiveCallTo("Query").With("arg").AndReturn("ARG"))
+			AllowDouble(collaborator).To(ReceiveCallTo("Query").With("other arg").AndReturn("other arg"))
+			AllowDouble(collaborator).To(ReceiveCallTo("Command").With("").AndReturnError(errors.New("empty command")))
+			AllowDouble(collaborator).To(ReceiveCallTo("VariadicQuery").With([]string{"a", "b"}).AndReturn("a,b"))
+
+			Expect(contract.Save(cassette)).To(Succeed())
+
+			mismatches, err := VerifyContract(cassette, CollaboratorDouble{}, &realCollaborator{})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mismatches).To(HaveLen(1))
+			Expect(mismatches[0].String()).To(Equal(`Query("other arg"): stubbed to return "other arg", actually returned "OTHER ARG"`))
+		})
+
+		It("reports calls the real implementation can't handle", func() {
+			AllowDouble(collaborator).To(ReceiveCallTo("Query").With("arg").AndReturn("ARG"))
+			Expect(contract.Save(cassette)).To(Succeed())
+
+			mismatches, err := VerifyContract(cassette, CollaboratorDouble{}, struct{ Query func(string) string }{})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mismatches).To(HaveLen(1))
+			Expect(mismatches[0].Err).To(MatchError("'struct { Query func(string) string }' has no method 'Query'"))
+		})
+	})
+})
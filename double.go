@@ -1,47 +1,238 @@
 package moka
 
 import (
-	"errors"
 	"fmt"
 	"reflect"
+	"sync"
+	"time"
 )
 
-// Double is the interface implemented by all Moka double types.
+// Double is the interface implemented by all Moka double types. It can be
+// implemented outside of Moka to provide custom doubles, which can then be
+// used with the whole DSL. Optional features of the DSL require the double to
+// also implement the corresponding capability interface, e.g.
+// `NegativeInteractionDouble` for `ExpectDouble(...).NotTo(...)`.
 type Double interface {
-	addInteraction(interaction interaction)
+	// AddInteraction configures an interaction on the double. If the
+	// interaction is invalid, it fails the test and returns the error.
+	AddInteraction(interaction Interaction) error
+
+	// Call performs a method call on the double.
 	Call(methodName string, args ...interface{}) ([]interface{}, error)
-	verifyInteractions()
+
+	// VerifyInteractions fails the test if any configured interaction hasn't
+	// been satisfied, and returns the error.
+	VerifyInteractions() error
+}
+
+// NegativeInteractionDouble is implemented by doubles supporting negative
+// expectations, configured with `ExpectDouble(...).NotTo(...)`.
+type NegativeInteractionDouble interface {
+	// AddNegativeInteraction configures an interaction whose matching calls
+	// will make the test fail. If the interaction is invalid, it fails the
+	// test and returns the error.
+	AddNegativeInteraction(interaction Interaction) error
 }
 
+// DefaultInteractionDouble is implemented by doubles supporting default
+// interactions, configured with `AllowDouble(...).ByDefault(...)`.
+type DefaultInteractionDouble interface {
+	// AddDefaultInteraction configures an interaction that only matches
+	// calls not matched by any other interaction. If the interaction is
+	// invalid, it fails the test and returns the error.
+	AddDefaultInteraction(interaction Interaction) error
+}
+
+// ExhaustiveDouble is implemented by doubles supporting
+// `VerifyNoMoreInteractions` and `ReportUnusedAllowances`.
+type ExhaustiveDouble interface {
+	// VerifyNoMoreInteractions fails the test if any received call hasn't
+	// been verified by an expectation, and returns the error.
+	VerifyNoMoreInteractions() error
+
+	// ReportUnusedAllowances makes VerifyInteractions also fail the test for
+	// allowances that haven't been used.
+	ReportUnusedAllowances()
+}
+
+// ResettableDouble is implemented by doubles supporting `RemoveInteractions`,
+// `ResetDouble`, `OpenScope` and `WithInteractions`.
+type ResettableDouble interface {
+	// RemoveInteractions removes all interactions configured for a method.
+	RemoveInteractions(methodName string)
+
+	// Reset removes all configured interactions and received calls, and
+	// clears the state of the double.
+	Reset()
+
+	// OpenScope opens a scope collecting all interactions configured on the
+	// double until it is closed. Closing the scope removes them.
+	OpenScope() Scope
+}
+
+// AsyncDouble is implemented by doubles supporting `VerifyCallsWithin`,
+// `WaitForCall` and `NotifyCalls`.
+type AsyncDouble interface {
+	// VerifyInteractionsWithin is like VerifyInteractions, but waits up to
+	// the specified timeout for the interactions to be satisfied.
+	VerifyInteractionsWithin(timeout time.Duration) error
+
+	// WaitForCall waits up to the specified timeout for a call to a method
+	// and returns it.
+	WaitForCall(methodName string, timeout time.Duration) Call
+
+	// NotifyCalls relays all calls received by the double to a channel.
+	NotifyCalls(channel chan<- Call)
+}
+
+// CallLogDouble is implemented by doubles supporting `MatchCallLogSnapshot`.
+type CallLogDouble interface {
+	// Calls returns the log of the calls received by the double, in the
+	// order they have been received.
+	Calls() []Call
+}
+
+// StatefulDouble is implemented by doubles supporting `SetDoubleState` and
+// interactions configured with `InState` and `TransitionTo`.
+type StatefulDouble interface {
+	// SetState moves the double to a state, enabling the interactions
+	// configured for that state.
+	SetState(state string)
+}
+
+var doubleInterfaceType = reflect.TypeOf((*Double)(nil)).Elem()
+
+// capability returns the implementation of an optional capability of a
+// double, looking through the doubles it embeds, e.g. the `Double` embedded
+// in a custom double type. If no implementation is found, it fails the test
+// using the global fail handler.
+func capability[C any](double Double, feature string) (C, bool) {
+	capable, isCapable := lookupCapability[C](double)
+	if !isCapable {
+		globalFail(fmt.Sprintf("Double of type '%T' doesn't support %s", double, feature), 3)
+	}
+
+	return capable, isCapable
+}
+
+// lookupCapability is like `capability`, but it doesn't fail the test if no
+// implementation is found.
+func lookupCapability[C any](double Double) (C, bool) {
+	for current := double; current != nil; current = embeddedDouble(current) {
+		if capable, isCapable := current.(C); isCapable {
+			return capable, true
+		}
+	}
+
+	var unsupported C
+	return unsupported, false
+}
+
+// embeddedDouble returns the non-nil `Double` embedded in a struct double, if
+// any.
+func embeddedDouble(double Double) Double {
+	value := reflect.Indirect(reflect.ValueOf(double))
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if !field.Anonymous || !field.IsExported() || !field.Type.Implements(doubleInterfaceType) {
+			continue
+		}
+
+		embedded, isDouble := value.Field(i).Interface().(Double)
+		if isDouble && embedded != nil {
+			return embedded
+		}
+	}
+
+	return nil
+}
+
+// DefaultCallLogSize is the number of most recent calls a `StrictDouble`
+// keeps in its call log, unless changed with `LimitCallLog`.
+const DefaultCallLogSize = 10000
+
 // StrictDouble is a strict implementation of the Double interface.
 // Any invocation of the `Call` method that won't match any of the configured
 // interactions will trigger a test failure and return an error.
 type StrictDouble struct {
-	interactions         []interaction
-	interactionValidator interactionValidator
-	failHandler          FailHandler
+	name                     string
+	doubleType               reflect.Type
+	interactions             []*configuredInteraction
+	interactionIndex         *interactionIndex
+	nextSequence             int
+	negativeInteractions     []*configuredInteraction
+	defaultInteractions      []*configuredInteraction
+	defaultInteractionIndex  *interactionIndex
+	state                    *doubleState
+	interactionValidator     InteractionValidator
+	failHandler              FailHandler
+	calls                    []*receivedCall
+	callLogSize              int
+	numberOfCalls            int
+	lastCalls                map[string]Call
+	unverifiedCalls          int
+	callListeners            []chan<- Call
+	changed                  chan struct{}
+	unusedAllowancesReported bool
+	mutex                    sync.Mutex
+}
+
+// configuredInteraction keeps track of how many calls an interaction
+// configured on a double has matched. The sequence number orders interactions
+// by configuration time.
+type configuredInteraction struct {
+	interaction     Interaction
+	sequence        int
+	numberOfMatches int
+}
+
+// receivedCall is an entry of the call log of a double. A call is verified
+// when it has been matched by an expectation.
+type receivedCall struct {
+	call     Call
+	verified bool
 }
 
 // NewStrictDouble instantiates a new `StrictDouble`, using the global fail
 // handler and no validation on the configured interactions.
 func NewStrictDouble() *StrictDouble {
-	return newStrictDoubleWithInteractionValidatorAndFailHandler(
-		newNullInteractionValidator(),
+	return NewStrictDoubleWithInteractionValidatorAndFailHandler(
+		NewNullInteractionValidator(),
 		globalFailHandler,
 	)
 }
 
+// NewStrictDoubleWithName instantiates a new `StrictDouble`, using the global
+// fail handler and no validation on the configured interactions. The name is
+// reported in the errors returned by the double.
+func NewStrictDoubleWithName(name string) *StrictDouble {
+	double := NewStrictDouble()
+	double.name = name
+	return double
+}
+
 // NewStrictDoubleWithTypeOf instantiates a new `StrictDouble`, using the
 // global fail handler and validating that any configured interaction matches
-// the specified type.
+// the specified type. The name of the type is reported in the errors returned
+// by the double.
 func NewStrictDoubleWithTypeOf(value interface{}) *StrictDouble {
-	return newStrictDoubleWithInteractionValidatorAndFailHandler(
-		newTypeInteractionValidator(reflect.TypeOf(value)),
+	t := reflect.TypeOf(value)
+	double := NewStrictDoubleWithInteractionValidatorAndFailHandler(
+		NewTypeInteractionValidator(t),
 		globalFailHandler,
 	)
+	double.name = typeString(t)
+	double.doubleType = t
+	return double
 }
 
-func newStrictDoubleWithInteractionValidatorAndFailHandler(interactionValidator interactionValidator, failHandler FailHandler) *StrictDouble {
+// NewStrictDoubleWithInteractionValidatorAndFailHandler instantiates a new
+// `StrictDouble`, using a custom interaction validator and fail handler.
+func NewStrictDoubleWithInteractionValidatorAndFailHandler(interactionValidator InteractionValidator, failHandler FailHandler) *StrictDouble {
 	if failHandler == nil {
 		panic("You are trying to instantiate a double, but Moka's fail handler is nil.\n" +
 			"If you're using Ginkgo, make sure you instantiate your doubles in a BeforeEach(), JustBeforeEach() or It() block.\n" +
@@ -49,46 +240,597 @@ func newStrictDoubleWithInteractionValidatorAndFailHandler(interactionValidator
 	}
 
 	return &StrictDouble{
-		interactions:         []interaction{},
-		interactionValidator: interactionValidator,
-		failHandler:          failHandler,
+		interactions:            []*configuredInteraction{},
+		interactionIndex:        newInteractionIndex(nil),
+		negativeInteractions:    []*configuredInteraction{},
+		defaultInteractions:     []*configuredInteraction{},
+		defaultInteractionIndex: newInteractionIndex(nil),
+		state:                   &doubleState{},
+		interactionValidator:    interactionValidator,
+		failHandler:             failHandler,
+		calls:                   []*receivedCall{},
+		callLogSize:             DefaultCallLogSize,
+		lastCalls:               map[string]Call{},
 	}
 }
 
 // Call performs a method call on the double. If a matching interaction is
-// found, its return values will be returned. If no configured interaction
-// matches, an error will be returned.
+// found, its return values will be returned. When multiple interactions
+// match, the most recently configured one wins, even if it is an allowance
+// shadowing an expectation. If no configured interaction matches, the
+// matching interaction fails, or the call matches a negative expectation, an
+// error will be returned.
 func (d *StrictDouble) Call(methodName string, args ...interface{}) ([]interface{}, error) {
-	for _, interaction := range d.interactions {
-		interactionReturnValues, interactionMatches := interaction.call(methodName, args)
+	receivedCall, candidates := d.recordCall(methodName, args)
+	remainingCandidates := candidates
+
+	for candidate, found := remainingCandidates.next(); found; candidate, found = remainingCandidates.next() {
+		interactionReturnValues, interactionMatches, interactionError := candidate.interaction.Call(methodName, args)
 		if interactionMatches {
+			d.recordMatch(receivedCall, candidate)
+
+			if panicking, isPanic := interactionError.(interactionPanic); isPanic {
+				panic(panicking.value)
+			}
+
+			if interactionError != nil {
+				interactionError = withDoubleName(interactionError, d.name)
+				d.fail(interactionError.Error())
+				return nil, interactionError
+			}
+
 			return interactionReturnValues, nil
 		}
 	}
 
-	errorMessage := fmt.Sprintf("Unexpected interaction: %s", formatMethodCall(methodName, args))
-	d.fail(errorMessage)
-	return nil, errors.New(errorMessage)
+	err := &UnexpectedCallError{
+		DoubleName: d.name,
+		MethodName: methodName,
+		Args:       args,
+		Candidates: candidates.interactions(),
+		State:      d.state.get(),
+	}
+	d.mutex.Lock()
+	if err.State != "" || anyStateScoped(d.interactions) || anyStateScoped(d.defaultInteractions) {
+		err.Stateful = true
+		err.AllowedCalls = append(allowedInState(d.interactions, err.State), allowedInState(d.defaultInteractions, err.State)...)
+	}
+	d.mutex.Unlock()
+	d.fail(err.Error())
+	return nil, err
+}
+
+// recordCall adds a call to the call log, notifies all listeners and returns
+// the interactions to match the call against, in order of precedence.
+// Interactions are called without holding the lock, as they might block.
+func (d *StrictDouble) recordCall(methodName string, args []interface{}) (*receivedCall, callCandidates) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return d.logCallLocked(methodName, args), d.candidatesLocked(methodName, args)
+}
+
+// recordUnhandledCall adds a call that hasn't reached the double to its call
+// log, e.g. a call failed by a `FaultInjector`, without matching it against
+// the configured interactions.
+func (d *StrictDouble) recordUnhandledCall(methodName string, args []interface{}) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.logCallLocked(methodName, args)
+}
+
+// logCallLocked adds a call to the call log of the double, dropping the
+// oldest call if the log is full, and notifies the sequence recorder and the
+// call listeners.
+func (d *StrictDouble) logCallLocked(methodName string, args []interface{}) *receivedCall {
+	call := Call{MethodName: methodName, Args: args, Index: d.numberOfCalls}
+	if lastCall, found := d.lastCalls[methodName]; found {
+		call.MethodIndex = lastCall.MethodIndex + 1
+	}
+	d.numberOfCalls++
+	d.lastCalls[methodName] = call
+	d.unverifiedCalls++
+
+	receivedCall := &receivedCall{call: call}
+	if d.callLogSize > 0 {
+		if len(d.calls) >= d.callLogSize {
+			d.calls = d.calls[len(d.calls)-d.callLogSize+1:]
+		}
+		d.calls = append(d.calls, receivedCall)
+	}
+
+	if globalSequenceRecorder != nil {
+		globalSequenceRecorder.record(d, call)
+	}
+
+	for _, listener := range d.callListeners {
+		select {
+		case listener <- call:
+		default:
+		}
+	}
+
+	d.notifyChangeLocked()
+
+	return receivedCall
 }
 
-func (d *StrictDouble) addInteraction(interaction interaction) {
-	validationError := d.interactionValidator.validate(interaction)
+// candidatesLocked returns the interactions to match a call against, in order
+// of precedence:
+//
+//  1. negative expectations, which make the call fail;
+//  2. regular interactions that could match the call according to the
+//     index, from the most recently configured;
+//  3. default interactions that could match the call, from the most recently
+//     configured, which are only used when no regular interaction matches.
+func (d *StrictDouble) candidatesLocked(methodName string, args []interface{}) callCandidates {
+	return callCandidates{
+		negative: d.negativeInteractions,
+		regular:  d.interactionIndex.candidates(methodName, args),
+		defaults: d.defaultInteractionIndex.candidates(methodName, args),
+	}
+}
+
+// callCandidates iterates over the interactions to match a call against,
+// without copying them. Being a value, it can be copied to iterate again.
+type callCandidates struct {
+	negative []*configuredInteraction
+	regular  candidateCursor
+	defaults candidateCursor
+}
+
+func (c *callCandidates) next() (*configuredInteraction, bool) {
+	if len(c.negative) > 0 {
+		candidate := c.negative[0]
+		c.negative = c.negative[1:]
+		return candidate, true
+	}
+
+	if candidate, found := c.regular.next(); found {
+		return candidate, true
+	}
+
+	return c.defaults.next()
+}
+
+// interactions returns all candidate interactions, in order of precedence.
+func (c callCandidates) interactions() []Interaction {
+	interactions := []Interaction{}
+	for candidate, found := c.next(); found; candidate, found = c.next() {
+		interactions = append(interactions, candidate.interaction)
+	}
+
+	return interactions
+}
+
+func (d *StrictDouble) recordMatch(receivedCall *receivedCall, matchingInteraction *configuredInteraction) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	matchingInteraction.numberOfMatches++
+	if !receivedCall.verified && verifiesCalls(matchingInteraction.interaction) {
+		receivedCall.verified = true
+		d.unverifiedCalls--
+	}
+
+	d.notifyChangeLocked()
+}
+
+func verifiesCalls(interaction Interaction) bool {
+	switch interaction.(type) {
+	case *expectedInteraction, *negativeExpectedInteraction:
+		return true
+	}
+
+	return false
+}
+
+func (d *StrictDouble) allInteractionsLocked() []*configuredInteraction {
+	allInteractions := append([]*configuredInteraction{}, d.negativeInteractions...)
+	allInteractions = append(allInteractions, d.interactions...)
+	return append(allInteractions, d.defaultInteractions...)
+}
+
+// AddInteraction validates an interaction and configures it on the double.
+func (d *StrictDouble) AddInteraction(interaction Interaction) error {
+	err := d.prepare(interaction)
+	if err != nil {
+		return err
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	configuredInteraction := &configuredInteraction{interaction: interaction, sequence: d.nextSequence}
+	d.nextSequence++
+	d.interactions = append(d.interactions, configuredInteraction)
+	d.interactionIndex.add(configuredInteraction)
+	return nil
+}
+
+// AddDefaultInteraction validates an interaction and configures it on the
+// double as a default, only matching calls not matched by any other
+// interaction.
+func (d *StrictDouble) AddDefaultInteraction(interaction Interaction) error {
+	err := d.prepare(interaction)
+	if err != nil {
+		return err
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	configuredInteraction := &configuredInteraction{interaction: interaction, sequence: d.nextSequence}
+	d.nextSequence++
+	d.defaultInteractions = append(d.defaultInteractions, configuredInteraction)
+	d.defaultInteractionIndex.add(configuredInteraction)
+	return nil
+}
+
+// prepare validates an interaction, failing the test if it is invalid, and
+// binds it to the double.
+func (d *StrictDouble) prepare(interaction Interaction) error {
+	validationError := d.validate(interaction)
 
 	if validationError != nil {
 		d.fail(validationError.Error())
-		return
+		return validationError
+	}
+
+	if globalContract != nil && d.doubleType != nil {
+		globalContract.record(d.doubleType, interaction)
+	}
+
+	bindState(interaction, d.state)
+	return nil
+}
+
+// AddNegativeInteraction validates an interaction and configures it on the
+// double as a negative expectation.
+func (d *StrictDouble) AddNegativeInteraction(interaction Interaction) error {
+	validationError := d.validate(interaction)
+
+	if validationError != nil {
+		d.fail(validationError.Error())
+		return validationError
 	}
 
-	d.interactions = append(d.interactions, interaction)
+	bindState(interaction, d.state)
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.negativeInteractions = append(d.negativeInteractions, &configuredInteraction{interaction: interaction, sequence: d.nextSequence})
+	d.nextSequence++
+	return nil
+}
+
+// validate validates an interaction, on its own and with the interaction
+// validator, filling in the details of the double in the returned error.
+func (d *StrictDouble) validate(interaction Interaction) error {
+	err := validateInteraction(interaction)
+	if err == nil {
+		err = d.interactionValidator.Validate(interaction)
+	}
+
+	if invalidInteractionError, isInvalid := err.(*InvalidInteractionError); isInvalid {
+		namedErr := *invalidInteractionError
+		namedErr.DoubleName = d.name
+		namedErr.Interaction = interaction
+		return &namedErr
+	}
+
+	return err
+}
+
+// RemoveInteractions removes all interactions configured for a method.
+func (d *StrictDouble) RemoveInteractions(methodName string) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.interactions = withoutMethod(d.interactions, methodName)
+	d.interactionIndex = newInteractionIndex(d.interactions)
+	d.negativeInteractions = withoutMethod(d.negativeInteractions, methodName)
+	d.defaultInteractions = withoutMethod(d.defaultInteractions, methodName)
+	d.defaultInteractionIndex = newInteractionIndex(d.defaultInteractions)
 }
 
-func (d *StrictDouble) verifyInteractions() {
-	for _, interaction := range d.interactions {
-		err := interaction.verify()
+func withoutMethod(configuredInteractions []*configuredInteraction, methodName string) []*configuredInteraction {
+	remainingInteractions := []*configuredInteraction{}
+	for _, configuredInteraction := range configuredInteractions {
+		if configuredInteraction.interaction.MethodName() != methodName {
+			remainingInteractions = append(remainingInteractions, configuredInteraction)
+		}
+	}
+	return remainingInteractions
+}
+
+// OpenScope opens a scope collecting all interactions configured on the
+// double until it is closed, including expected and negative ones. Closing
+// the scope removes them.
+func (d *StrictDouble) OpenScope() Scope {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return &strictDoubleScope{double: d, sequence: d.nextSequence}
+}
+
+// closeScope verifies the expectations configured after the specified
+// sequence number, then removes all interactions configured after it.
+func (d *StrictDouble) closeScope(sequence int) error {
+	d.mutex.Lock()
+	scopedInteractions := configuredSince(d.allInteractionsLocked(), sequence)
+	d.mutex.Unlock()
+
+	var err error
+	for _, configuredInteraction := range scopedInteractions {
+		err = configuredInteraction.interaction.Verify()
 		if err != nil {
-			d.fail(err.Error())
-			return
+			err = withDoubleName(err, d.name)
+			break
+		}
+	}
+
+	d.removeInteractionsSince(sequence)
+
+	if err != nil {
+		d.fail(err.Error())
+	}
+
+	return err
+}
+
+// removeInteractionsSince removes all interactions configured after the
+// specified sequence number.
+func (d *StrictDouble) removeInteractionsSince(sequence int) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.interactions = configuredBefore(d.interactions, sequence)
+	d.interactionIndex = newInteractionIndex(d.interactions)
+	d.negativeInteractions = configuredBefore(d.negativeInteractions, sequence)
+	d.defaultInteractions = configuredBefore(d.defaultInteractions, sequence)
+	d.defaultInteractionIndex = newInteractionIndex(d.defaultInteractions)
+}
+
+func configuredBefore(configuredInteractions []*configuredInteraction, sequence int) []*configuredInteraction {
+	remainingInteractions := []*configuredInteraction{}
+	for _, configuredInteraction := range configuredInteractions {
+		if configuredInteraction.sequence < sequence {
+			remainingInteractions = append(remainingInteractions, configuredInteraction)
+		}
+	}
+	return remainingInteractions
+}
+
+func configuredSince(configuredInteractions []*configuredInteraction, sequence int) []*configuredInteraction {
+	scopedInteractions := []*configuredInteraction{}
+	for _, configuredInteraction := range configuredInteractions {
+		if configuredInteraction.sequence >= sequence {
+			scopedInteractions = append(scopedInteractions, configuredInteraction)
+		}
+	}
+	return scopedInteractions
+}
+
+// Reset removes all configured interactions and received calls, and clears
+// the state of the double.
+func (d *StrictDouble) Reset() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.interactions = []*configuredInteraction{}
+	d.interactionIndex = newInteractionIndex(nil)
+	d.negativeInteractions = []*configuredInteraction{}
+	d.defaultInteractions = []*configuredInteraction{}
+	d.defaultInteractionIndex = newInteractionIndex(nil)
+	d.calls = []*receivedCall{}
+	d.numberOfCalls = 0
+	d.lastCalls = map[string]Call{}
+	d.unverifiedCalls = 0
+	d.state.set("")
+}
+
+// SetState moves the double to a state, enabling the interactions configured
+// for that state.
+func (d *StrictDouble) SetState(state string) {
+	d.state.set(state)
+}
+
+// VerifyInteractions fails the test if any configured interaction hasn't
+// been satisfied.
+func (d *StrictDouble) VerifyInteractions() error {
+	err := d.checkInteractions()
+	if err != nil {
+		d.fail(err.Error())
+	}
+
+	return err
+}
+
+func (d *StrictDouble) checkInteractions() error {
+	d.mutex.Lock()
+	configuredInteractions := d.allInteractionsLocked()
+	unusedAllowancesReported := d.unusedAllowancesReported
+	d.mutex.Unlock()
+
+	for _, configuredInteraction := range configuredInteractions {
+		err := configuredInteraction.interaction.Verify()
+		if err != nil {
+			return withDoubleName(err, d.name)
+		}
+	}
+
+	if unusedAllowancesReported {
+		return d.checkUnusedAllowances()
+	}
+
+	return nil
+}
+
+func (d *StrictDouble) checkUnusedAllowances() error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	for _, configuredInteraction := range d.interactions {
+		if configuredInteraction.numberOfMatches == 0 && !verifiesCalls(configuredInteraction.interaction) {
+			return &UnusedAllowanceError{
+				DoubleName:  d.name,
+				MethodName:  configuredInteraction.interaction.MethodName(),
+				Interaction: configuredInteraction.interaction,
+			}
+		}
+	}
+
+	return nil
+}
+
+// ReportUnusedAllowances makes VerifyInteractions also fail the test for
+// allowances that haven't been used.
+func (d *StrictDouble) ReportUnusedAllowances() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.unusedAllowancesReported = true
+}
+
+// VerifyNoMoreInteractions fails the test if any received call hasn't been
+// verified by an expectation.
+func (d *StrictDouble) VerifyNoMoreInteractions() error {
+	d.mutex.Lock()
+	unverifiedCalls := []Call{}
+	for _, receivedCall := range d.calls {
+		if !receivedCall.verified {
+			unverifiedCalls = append(unverifiedCalls, receivedCall.call)
+		}
+	}
+	droppedCalls := d.unverifiedCalls - len(unverifiedCalls)
+	d.mutex.Unlock()
+
+	if len(unverifiedCalls) > 0 || droppedCalls > 0 {
+		err := &UnverifiedCallsError{DoubleName: d.name, Calls: unverifiedCalls, DroppedCalls: droppedCalls}
+		d.fail(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// VerifyInteractionsWithin waits up to the specified timeout for all
+// configured interactions to be satisfied, failing the test if they aren't.
+func (d *StrictDouble) VerifyInteractionsWithin(timeout time.Duration) error {
+	deadline := time.After(timeout)
+
+	for {
+		changed := d.changes()
+
+		err := d.checkInteractions()
+		if err == nil {
+			return nil
+		}
+
+		select {
+		case <-changed:
+		case <-deadline:
+			timeoutErr := &VerificationTimeoutError{DoubleName: d.name, Timeout: timeout, Err: err}
+			d.fail(timeoutErr.Error())
+			return timeoutErr
+		}
+	}
+}
+
+// WaitForCall waits up to the specified timeout for a call to a method and
+// returns the most recent one.
+func (d *StrictDouble) WaitForCall(methodName string, timeout time.Duration) Call {
+	deadline := time.After(timeout)
+
+	for {
+		changed := d.changes()
+
+		call, found := d.lastCallTo(methodName)
+		if found {
+			return call
 		}
+
+		select {
+		case <-changed:
+		case <-deadline:
+			d.fail(fmt.Sprintf("Timed out after %s waiting for a call to %s", timeout, methodName))
+			return Call{}
+		}
+	}
+}
+
+func (d *StrictDouble) lastCallTo(methodName string) (Call, bool) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	call, found := d.lastCalls[methodName]
+	return call, found
+}
+
+// Calls returns the log of the calls received by the double, in the order
+// they have been received. Only the most recent calls are kept, up to the
+// size of the call log.
+func (d *StrictDouble) Calls() []Call {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	calls := []Call{}
+	for _, receivedCall := range d.calls {
+		calls = append(calls, receivedCall.call)
+	}
+
+	return calls
+}
+
+// LimitCallLog changes the number of most recent calls kept in the call log
+// of the double, dropping older calls if needed. A size of 0 disables the call
+// log. Unverified calls dropped from the log are still reported by
+// `VerifyNoMoreInteractions`, by number.
+func (d *StrictDouble) LimitCallLog(size int) {
+	if size < 0 {
+		d.fail(fmt.Sprintf("You are trying to limit the call log to %d calls, but the size of the call log can't be negative.", size))
+		return
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.callLogSize = size
+	if len(d.calls) > size {
+		d.calls = append([]*receivedCall{}, d.calls[len(d.calls)-size:]...)
+	}
+}
+
+// NotifyCalls relays all calls received by the double to a channel. Sends
+// are non-blocking, so the channel should be buffered.
+func (d *StrictDouble) NotifyCalls(channel chan<- Call) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.callListeners = append(d.callListeners, channel)
+}
+
+// changes returns a channel that will be closed on the next change to the
+// state of the double, i.e. when a call is received or matched. The channel
+// is only allocated when someone waits for a change.
+func (d *StrictDouble) changes() <-chan struct{} {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.changed == nil {
+		d.changed = make(chan struct{})
+	}
+
+	return d.changed
+}
+
+func (d *StrictDouble) notifyChangeLocked() {
+	if d.changed != nil {
+		close(d.changed)
+		d.changed = nil
 	}
 }
 
@@ -2,6 +2,9 @@ package moka
 
 import (
 	"errors"
+	"fmt"
+	"testing"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -32,12 +35,14 @@ var _ = Describe("StrictDouble", func() {
 	})
 
 	JustBeforeEach(func() {
-		double = newStrictDoubleWithInteractionValidatorAndFailHandler(interactionValidator, testFailHandler)
+		double = NewStrictDoubleWithInteractionValidatorAndFailHandler(interactionValidator, testFailHandler)
 	})
 
-	Describe("addInteraction", func() {
+	Describe("AddInteraction", func() {
+		var addInteractionError error
+
 		JustBeforeEach(func() {
-			double.addInteraction(newFakeInteraction([]interface{}{"result"}, true, nil, nil))
+			addInteractionError = double.AddInteraction(newFakeInteraction([]interface{}{"result"}, true, nil, nil))
 		})
 
 		Context("when the interaction is valid", func() {
@@ -48,6 +53,7 @@ var _ = Describe("StrictDouble", func() {
 			It("succeeds", func() {
 				By("not making the test fail", func() {
 					Expect(testFailHandlerInvoked).To(BeFalse())
+					Expect(addInteractionError).NotTo(HaveOccurred())
 				})
 
 				By("adding the interaction to the double", func() {
@@ -70,6 +76,10 @@ var _ = Describe("StrictDouble", func() {
 					Expect(testFailMessage).To(Equal("invalid interaction"))
 				})
 
+				By("returning the error", func() {
+					Expect(addInteractionError).To(MatchError("invalid interaction"))
+				})
+
 				By("not adding the interaction to the double", func() {
 					result, err := double.Call("", []interface{}{})
 
@@ -89,25 +99,25 @@ var _ = Describe("StrictDouble", func() {
 		var err error
 
 		JustBeforeEach(func() {
-			double.addInteraction(firstInteraction)
-			double.addInteraction(secondInteraction)
-			double.addInteraction(thirdInteraction)
+			double.AddInteraction(firstInteraction)
+			double.AddInteraction(secondInteraction)
+			double.AddInteraction(thirdInteraction)
 
 			returnValues, err = double.Call("UltimateQuestion", "life", "universe", "everything")
 		})
 
 		Context("when some interactions match", func() {
 			BeforeEach(func() {
-				firstInteraction = newFakeInteraction(nil, false, nil, nil)
+				firstInteraction = newFakeInteraction([]interface{}{41, nil}, true, nil, nil)
 				secondInteraction = newFakeInteraction([]interface{}{42, nil}, true, nil, nil)
-				thirdInteraction = newFakeInteraction([]interface{}{43, nil}, true, nil, nil)
+				thirdInteraction = newFakeInteraction(nil, false, nil, nil)
 			})
 
 			It("returns the configured return values", func() {
-				By("stopping at the first matching interaction", func() {
-					Expect(firstInteraction.callCalled).To(BeTrue())
+				By("stopping at the most recently configured matching interaction", func() {
+					Expect(thirdInteraction.callCalled).To(BeTrue())
 					Expect(secondInteraction.callCalled).To(BeTrue())
-					Expect(thirdInteraction.callCalled).To(BeFalse())
+					Expect(firstInteraction.callCalled).To(BeFalse())
 				})
 
 				By("returning its return values", func() {
@@ -124,6 +134,34 @@ var _ = Describe("StrictDouble", func() {
 			})
 		})
 
+		Context("when the matching interaction fails", func() {
+			BeforeEach(func() {
+				firstInteraction = newFakeInteraction([]interface{}{41, nil}, true, nil, nil)
+				secondInteraction = newFakeInteraction([]interface{}{42, nil}, true, nil, nil)
+				secondInteraction.callError = errors.New("interaction failed")
+				thirdInteraction = newFakeInteraction(nil, false, nil, nil)
+			})
+
+			It("makes the test fail", func() {
+				By("stopping at the failing interaction", func() {
+					Expect(firstInteraction.callCalled).To(BeFalse())
+				})
+
+				By("returning nil", func() {
+					Expect(returnValues).To(BeNil())
+				})
+
+				By("calling the fail handler", func() {
+					Expect(testFailHandlerInvoked).To(BeTrue())
+					Expect(testFailMessage).To(Equal("interaction failed"))
+				})
+
+				By("returning the error", func() {
+					Expect(err).To(MatchError("interaction failed"))
+				})
+			})
+		})
+
 		Context("when no interaction matches", func() {
 			BeforeEach(func() {
 				firstInteraction = newFakeInteraction(nil, false, nil, nil)
@@ -150,21 +188,462 @@ var _ = Describe("StrictDouble", func() {
 				By("returning an error", func() {
 					Expect(err).To(MatchError("Unexpected interaction: UltimateQuestion(\"life\", \"universe\", \"everything\")"))
 				})
+
+				By("returning an UnexpectedCallError listing the candidates", func() {
+					var unexpectedCallError *UnexpectedCallError
+					Expect(errors.As(err, &unexpectedCallError)).To(BeTrue())
+					Expect(unexpectedCallError.MethodName).To(Equal("UltimateQuestion"))
+					Expect(unexpectedCallError.Args).To(Equal([]interface{}{"life", "universe", "everything"}))
+					Expect(unexpectedCallError.Candidates).To(Equal([]Interaction{thirdInteraction, secondInteraction, firstInteraction}))
+				})
 			})
 		})
 	})
 
-	Describe("verifyInteractions", func() {
+	Describe("AddInteraction on a typed double", func() {
+		It("reports the double and the interaction in the returned errors", func() {
+			RegisterDoublesFailHandler(testFailHandler)
+			double := NewStrictDoubleWithTypeOf(myDeepThought{})
+			interaction := newArgsInteraction("UltimateGuess", nil, nil)
+
+			err := double.AddInteraction(interaction)
+
+			var invalidInteractionError *InvalidInteractionError
+			Expect(errors.As(err, &invalidInteractionError)).To(BeTrue())
+			Expect(invalidInteractionError.DoubleName).To(Equal("moka.myDeepThought"))
+			Expect(invalidInteractionError.MethodName).To(Equal("UltimateGuess"))
+			Expect(invalidInteractionError.Interaction).To(Equal(interaction))
+		})
+	})
+
+	Describe("Call on a named double", func() {
+		It("reports the name of the double in the returned errors", func() {
+			RegisterDoublesFailHandler(testFailHandler)
+			double := NewStrictDoubleWithName("deepThought")
+
+			_, err := double.Call("UltimateQuestion")
+
+			var unexpectedCallError *UnexpectedCallError
+			Expect(errors.As(err, &unexpectedCallError)).To(BeTrue())
+			Expect(unexpectedCallError.DoubleName).To(Equal("deepThought"))
+		})
+	})
+
+	Describe("RemoveInteractions", func() {
+		var ultimateQuestionInteraction *fakeInteraction
+		var negativeUltimateQuestionInteraction *fakeInteraction
+		var domandaFondamentaleInteraction *fakeInteraction
+
+		JustBeforeEach(func() {
+			ultimateQuestionInteraction = newFakeInteraction([]interface{}{42}, true, nil, nil)
+			ultimateQuestionInteraction.methodName = "UltimateQuestion"
+			negativeUltimateQuestionInteraction = newFakeInteraction(nil, false, nil, nil)
+			negativeUltimateQuestionInteraction.methodName = "UltimateQuestion"
+			domandaFondamentaleInteraction = newFakeInteraction([]interface{}{42}, true, nil, nil)
+			domandaFondamentaleInteraction.methodName = "DomandaFondamentale"
+
+			double.AddInteraction(domandaFondamentaleInteraction)
+			double.AddInteraction(ultimateQuestionInteraction)
+			double.AddNegativeInteraction(negativeUltimateQuestionInteraction)
+
+			double.RemoveInteractions("UltimateQuestion")
+			double.Call("UltimateQuestion")
+		})
+
+		It("removes all interactions for the method", func() {
+			Expect(ultimateQuestionInteraction.callCalled).To(BeFalse())
+			Expect(negativeUltimateQuestionInteraction.callCalled).To(BeFalse())
+			Expect(domandaFondamentaleInteraction.callCalled).To(BeTrue())
+		})
+	})
+
+	Describe("Reset", func() {
+		var interaction *fakeInteraction
+		var negativeInteraction *fakeInteraction
+
+		JustBeforeEach(func() {
+			interaction = newFakeInteraction([]interface{}{42}, true, nil, nil)
+			negativeInteraction = newFakeInteraction(nil, false, errors.New("nope"), nil)
+			double.AddInteraction(interaction)
+			double.AddNegativeInteraction(negativeInteraction)
+			double.Call("UltimateQuestion")
+			interaction.callCalled = false
+			negativeInteraction.callCalled = false
+
+			double.Reset()
+		})
+
+		It("removes all interactions and clears the call log", func() {
+			returnValues, err := double.Call("UltimateQuestion")
+
+			Expect(returnValues).To(BeNil())
+			Expect(err).To(HaveOccurred())
+			Expect(interaction.callCalled).To(BeFalse())
+			Expect(negativeInteraction.callCalled).To(BeFalse())
+
+			resetTestFail()
+			double.VerifyInteractions()
+			Expect(testFailHandlerInvoked).To(BeFalse())
+
+			double.VerifyNoMoreInteractions()
+			Expect(testFailMessage).To(Equal("Unverified interactions: UltimateQuestion()"))
+		})
+	})
+
+	Describe("Call with a panicking interaction", func() {
+		var panicValue interface{}
+
+		JustBeforeEach(func() {
+			interaction := newFakeInteraction(nil, true, nil, nil)
+			interaction.callError = interactionPanic{value: "don't panic"}
+			double.AddInteraction(interaction)
+
+			func() {
+				defer func() {
+					panicValue = recover()
+				}()
+
+				double.Call("UltimateQuestion")
+			}()
+		})
+
+		It("panics with the value", func() {
+			Expect(panicValue).To(Equal("don't panic"))
+			Expect(testFailHandlerInvoked).To(BeFalse())
+		})
+	})
+
+	Describe("AddNegativeInteraction", func() {
+		var negativeInteraction *fakeInteraction
+
+		BeforeEach(func() {
+			negativeInteraction = newFakeInteraction(nil, true, nil, nil)
+			negativeInteraction.callError = errors.New("forbidden")
+		})
+
+		JustBeforeEach(func() {
+			double.AddInteraction(newFakeInteraction([]interface{}{"result"}, true, nil, nil))
+			double.AddNegativeInteraction(negativeInteraction)
+		})
+
+		It("takes precedence over the other interactions", func() {
+			result, err := double.Call("UltimateQuestion")
+
+			Expect(result).To(BeNil())
+			Expect(err).To(MatchError("forbidden"))
+			Expect(testFailHandlerInvoked).To(BeTrue())
+			Expect(testFailMessage).To(Equal("forbidden"))
+		})
+
+		Context("when the interaction is not valid", func() {
+			BeforeEach(func() {
+				interactionValidator = newFakeInteractionValidator(errors.New("invalid interaction"))
+			})
+
+			It("fails and doesn't add the interaction", func() {
+				Expect(testFailMessage).To(Equal("invalid interaction"))
+				Expect(negativeInteraction.callCalled).To(BeFalse())
+			})
+		})
+	})
+
+	Describe("AddDefaultInteraction", func() {
+		JustBeforeEach(func() {
+			double.AddInteraction(newArgsInteraction("UltimateQuestion", []interface{}{"life"}, []interface{}{42}))
+			double.AddDefaultInteraction(newArgsInteraction("UltimateQuestion", nil, []interface{}{0}))
+			double.AddDefaultInteraction(newArgsInteraction("WorstQuestion", nil, []interface{}{-1}))
+			double.AddDefaultInteraction(newArgsInteraction("WorstQuestion", []interface{}{"life"}, []interface{}{-42}))
+		})
+
+		It("only matches calls no other interaction matches", func() {
+			Expect(double.Call("UltimateQuestion", "life")).To(Equal([]interface{}{42}))
+			Expect(double.Call("UltimateQuestion", "universe")).To(Equal([]interface{}{0}))
+			Expect(testFailHandlerInvoked).To(BeFalse(), testFailMessage)
+		})
+
+		It("lets the most recently configured default win", func() {
+			Expect(double.Call("WorstQuestion", "life")).To(Equal([]interface{}{-42}))
+			Expect(double.Call("WorstQuestion", "universe")).To(Equal([]interface{}{-1}))
+		})
+
+		It("doesn't take precedence over interactions configured later", func() {
+			double.AddInteraction(newArgsInteraction("WorstQuestion", nil, []interface{}{"specific"}))
+
+			Expect(double.Call("WorstQuestion", "life")).To(Equal([]interface{}{"specific"}))
+		})
+
+		It("is removed with the other interactions", func() {
+			double.RemoveInteractions("WorstQuestion")
+			_, err := double.Call("WorstQuestion", "life")
+			Expect(err).To(HaveOccurred())
+
+			double.Reset()
+			_, err = double.Call("UltimateQuestion", "universe")
+			Expect(err).To(HaveOccurred())
+		})
+
+		Context("when the interaction is not valid", func() {
+			var defaultInteraction *fakeInteraction
+
+			BeforeEach(func() {
+				interactionValidator = newFakeInteractionValidator(errors.New("invalid interaction"))
+				defaultInteraction = newFakeInteraction(nil, true, nil, nil)
+			})
+
+			It("fails and doesn't add the interaction", func() {
+				Expect(double.AddDefaultInteraction(defaultInteraction)).To(MatchError("invalid interaction"))
+				Expect(testFailMessage).To(Equal("invalid interaction"))
+
+				double.Call("UltimateQuestion", "universe")
+				Expect(defaultInteraction.callCalled).To(BeFalse())
+			})
+		})
+	})
+
+	Describe("VerifyNoMoreInteractions", func() {
+		JustBeforeEach(func() {
+			double.AddInteraction(newArgsInteraction("UltimateQuestion", []interface{}{"universe"}, nil))
+			double.AddInteraction(newExpectedInteraction(newArgsInteraction("UltimateQuestion", []interface{}{"life"}, nil)))
+		})
+
+		Context("when all calls have been matched by expectations", func() {
+			It("lets the test pass", func() {
+				double.Call("UltimateQuestion", "life")
+				double.VerifyNoMoreInteractions()
+
+				Expect(testFailHandlerInvoked).To(BeFalse())
+			})
+		})
+
+		Context("when some calls have not been matched by expectations", func() {
+			It("makes the test fail, reporting all unverified calls", func() {
+				double.Call("UltimateQuestion", "life")
+				double.Call("UltimateQuestion", "universe")
+				double.Call("UltimateQuestion", "everything")
+				resetTestFail()
+				err := double.VerifyNoMoreInteractions()
+
+				Expect(testFailHandlerInvoked).To(BeTrue())
+				Expect(testFailMessage).To(Equal("Unverified interactions: UltimateQuestion(\"universe\"), UltimateQuestion(\"everything\")"))
+
+				var unverifiedCallsError *UnverifiedCallsError
+				Expect(errors.As(err, &unverifiedCallsError)).To(BeTrue())
+				Expect(unverifiedCallsError.Calls).To(Equal([]Call{
+					{MethodName: "UltimateQuestion", Args: []interface{}{"universe"}, Index: 1, MethodIndex: 1},
+					{MethodName: "UltimateQuestion", Args: []interface{}{"everything"}, Index: 2, MethodIndex: 2},
+				}))
+			})
+		})
+	})
+
+	Describe("ReportUnusedAllowances", func() {
+		JustBeforeEach(func() {
+			double.AddInteraction(newArgsInteraction("UltimateQuestion", []interface{}{"life"}, nil))
+			double.AddInteraction(newArgsInteraction("UltimateQuestion", []interface{}{"universe"}, nil))
+			double.AddInteraction(newExpectedInteraction(newArgsInteraction("UltimateQuestion", []interface{}{"everything"}, nil)))
+			double.Call("UltimateQuestion", "life")
+			double.Call("UltimateQuestion", "everything")
+		})
+
+		Context("when enabled", func() {
+			It("makes verification fail on allowances that have never been matched", func() {
+				double.ReportUnusedAllowances()
+				err := double.VerifyInteractions()
+
+				Expect(testFailHandlerInvoked).To(BeTrue())
+				Expect(testFailMessage).To(Equal("Unused allowance: UltimateQuestion(\"universe\")"))
+
+				var unusedAllowanceError *UnusedAllowanceError
+				Expect(errors.As(err, &unusedAllowanceError)).To(BeTrue())
+				Expect(unusedAllowanceError.MethodName).To(Equal("UltimateQuestion"))
+			})
+		})
+
+		Context("when not enabled", func() {
+			It("doesn't report unused allowances", func() {
+				double.VerifyInteractions()
+
+				Expect(testFailHandlerInvoked).To(BeFalse())
+			})
+		})
+	})
+
+	Describe("VerifyInteractionsWithin", func() {
+		JustBeforeEach(func() {
+			double.AddInteraction(newExpectedInteraction(newArgsInteraction("UltimateQuestion", nil, nil)))
+		})
+
+		Context("when the expected call happens within the timeout", func() {
+			It("lets the test pass", func() {
+				go func() {
+					time.Sleep(10 * time.Millisecond)
+					double.Call("UltimateQuestion")
+				}()
+
+				double.VerifyInteractionsWithin(time.Second)
+
+				Expect(testFailHandlerInvoked).To(BeFalse())
+			})
+		})
+
+		Context("when the expected call doesn't happen within the timeout", func() {
+			It("makes the test fail", func() {
+				err := double.VerifyInteractionsWithin(10 * time.Millisecond)
+
+				Expect(testFailHandlerInvoked).To(BeTrue())
+				Expect(testFailMessage).To(Equal("Expected interaction: UltimateQuestion() (timed out after 10ms)"))
+
+				var timeoutError *VerificationTimeoutError
+				Expect(errors.As(err, &timeoutError)).To(BeTrue())
+				Expect(timeoutError.Timeout).To(Equal(10 * time.Millisecond))
+
+				var unmetExpectationError *UnmetExpectationError
+				Expect(errors.As(err, &unmetExpectationError)).To(BeTrue())
+				Expect(unmetExpectationError.MethodName).To(Equal("UltimateQuestion"))
+			})
+		})
+	})
+
+	Describe("WaitForCall", func() {
+		JustBeforeEach(func() {
+			double.AddInteraction(newArgsInteraction("UltimateQuestion", nil, nil))
+		})
+
+		Context("when the call happens within the timeout", func() {
+			It("returns the call", func() {
+				go func() {
+					time.Sleep(10 * time.Millisecond)
+					double.Call("UltimateQuestion", "life")
+				}()
+
+				call := double.WaitForCall("UltimateQuestion", time.Second)
+
+				Expect(call).To(Equal(Call{MethodName: "UltimateQuestion", Args: []interface{}{"life"}}))
+				Expect(testFailHandlerInvoked).To(BeFalse())
+			})
+		})
+
+		Context("when the call has already happened", func() {
+			It("returns the last call immediately", func() {
+				double.Call("UltimateQuestion", "life")
+				double.Call("UltimateQuestion", "universe")
+
+				call := double.WaitForCall("UltimateQuestion", 0)
+
+				Expect(call).To(Equal(Call{MethodName: "UltimateQuestion", Args: []interface{}{"universe"}, Index: 1, MethodIndex: 1}))
+				Expect(testFailHandlerInvoked).To(BeFalse())
+			})
+		})
+
+		Context("when the call doesn't happen within the timeout", func() {
+			It("makes the test fail", func() {
+				double.WaitForCall("UltimateQuestion", 10*time.Millisecond)
+
+				Expect(testFailHandlerInvoked).To(BeTrue())
+				Expect(testFailMessage).To(Equal("Timed out after 10ms waiting for a call to UltimateQuestion"))
+			})
+		})
+	})
+
+	Describe("Calls", func() {
+		It("returns all received calls, in order", func() {
+			double.AddInteraction(newArgsInteraction("UltimateQuestion", nil, nil))
+			double.Call("UltimateQuestion", "life")
+			double.Call("UnexpectedQuestion")
+
+			Expect(double.Calls()).To(Equal([]Call{
+				{MethodName: "UltimateQuestion", Args: []interface{}{"life"}, Index: 0},
+				{MethodName: "UnexpectedQuestion", Args: nil, Index: 1, MethodIndex: 0},
+			}))
+		})
+	})
+
+	Describe("LimitCallLog", func() {
+		JustBeforeEach(func() {
+			double.AddInteraction(newArgsInteraction("UltimateQuestion", nil, nil))
+		})
+
+		It("keeps only the most recent calls", func() {
+			double.LimitCallLog(2)
+			double.Call("UltimateQuestion", "life")
+			double.Call("UltimateQuestion", "universe")
+			double.Call("UltimateQuestion", "everything")
+
+			Expect(double.Calls()).To(Equal([]Call{
+				{MethodName: "UltimateQuestion", Args: []interface{}{"universe"}, Index: 1, MethodIndex: 1},
+				{MethodName: "UltimateQuestion", Args: []interface{}{"everything"}, Index: 2, MethodIndex: 2},
+			}))
+		})
+
+		It("drops the oldest calls when the log is shrunk", func() {
+			double.Call("UltimateQuestion", "life")
+			double.Call("UltimateQuestion", "universe")
+			double.LimitCallLog(1)
+
+			Expect(double.Calls()).To(Equal([]Call{
+				{MethodName: "UltimateQuestion", Args: []interface{}{"universe"}, Index: 1, MethodIndex: 1},
+			}))
+		})
+
+		Context("when the size is 0", func() {
+			It("disables the call log, but not waiting for calls", func() {
+				double.LimitCallLog(0)
+				double.Call("UltimateQuestion", "life")
+
+				Expect(double.Calls()).To(BeEmpty())
+				Expect(double.WaitForCall("UltimateQuestion", 0)).To(Equal(Call{MethodName: "UltimateQuestion", Args: []interface{}{"life"}}))
+			})
+
+			It("still reports the number of unverified calls", func() {
+				double.LimitCallLog(0)
+				double.Call("UltimateQuestion", "life")
+				err := double.VerifyNoMoreInteractions()
+
+				Expect(testFailHandlerInvoked).To(BeTrue())
+				Expect(testFailMessage).To(Equal("Unverified interactions: 1 more dropped from the call log"))
+				Expect(err.(*UnverifiedCallsError).DroppedCalls).To(Equal(1))
+			})
+		})
+
+		Context("when the size is negative", func() {
+			It("makes the test fail", func() {
+				double.LimitCallLog(-1)
+
+				Expect(testFailHandlerInvoked).To(BeTrue())
+				Expect(testFailMessage).To(Equal("You are trying to limit the call log to -1 calls, but the size of the call log can't be negative."))
+			})
+		})
+	})
+
+	Describe("NotifyCalls", func() {
+		It("sends all subsequent calls to the channel", func() {
+			double.AddInteraction(newArgsInteraction("UltimateQuestion", nil, nil))
+			double.Call("UltimateQuestion", "life")
+
+			calls := make(chan Call, 10)
+			double.NotifyCalls(calls)
+
+			double.Call("UltimateQuestion", "universe")
+			double.Call("UnexpectedQuestion")
+
+			Expect(calls).To(Receive(Equal(Call{MethodName: "UltimateQuestion", Args: []interface{}{"universe"}, Index: 1, MethodIndex: 1})))
+			Expect(calls).To(Receive(Equal(Call{MethodName: "UnexpectedQuestion", Args: nil, Index: 2, MethodIndex: 0})))
+			Expect(calls).NotTo(Receive())
+		})
+	})
+
+	Describe("VerifyInteractions", func() {
 		var firstInteraction *fakeInteraction
 		var secondInteraction *fakeInteraction
 		var thirdInteraction *fakeInteraction
+		var verifyError error
 
 		JustBeforeEach(func() {
-			double.addInteraction(firstInteraction)
-			double.addInteraction(secondInteraction)
-			double.addInteraction(thirdInteraction)
+			double.AddInteraction(firstInteraction)
+			double.AddInteraction(secondInteraction)
+			double.AddInteraction(thirdInteraction)
 
-			double.verifyInteractions()
+			verifyError = double.VerifyInteractions()
 		})
 
 		Context("when all interactions are verified", func() {
@@ -205,7 +684,51 @@ var _ = Describe("StrictDouble", func() {
 					Expect(testFailHandlerInvoked).To(BeTrue())
 					Expect(testFailMessage).To(Equal("nope"))
 				})
+
+				By("returning the error", func() {
+					Expect(verifyError).To(MatchError("nope"))
+				})
 			})
 		})
 	})
 })
+
+func BenchmarkCallWithManyLiteralInteractions(b *testing.B) {
+	double := NewStrictDoubleWithInteractionValidatorAndFailHandler(NewNullInteractionValidator(), testFailHandler)
+	for i := 0; i < 1000; i++ {
+		double.AddInteraction(newArgsInteraction("UltimateQuestion", []interface{}{fmt.Sprint(i)}, []interface{}{i}))
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		double.Call("UltimateQuestion", "0")
+	}
+}
+
+func BenchmarkCallWithManyMethods(b *testing.B) {
+	double := NewStrictDoubleWithInteractionValidatorAndFailHandler(NewNullInteractionValidator(), testFailHandler)
+	captor := NewCaptor[string]()
+	for i := 0; i < 1000; i++ {
+		double.AddInteraction(newArgsInteraction(fmt.Sprintf("Method%d", i), []interface{}{captor}, []interface{}{i}))
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		double.Call("Method0", "arg")
+	}
+}
+
+func BenchmarkCallWithManyUnindexedInteractions(b *testing.B) {
+	double := NewStrictDoubleWithInteractionValidatorAndFailHandler(NewNullInteractionValidator(), testFailHandler)
+	for i := 0; i < 1000; i++ {
+		double.AddInteraction(newArgsInteraction("UltimateQuestionWithSlice", []interface{}{[]string{fmt.Sprint(i)}}, []interface{}{i}))
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		double.Call("UltimateQuestionWithSlice", []string{"0"})
+	}
+}
@@ -0,0 +1,207 @@
+package moka
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// UnexpectedCallError is returned by `StrictDouble.Call` when a call doesn't
+// match any of the configured interactions, or when it matches a negative
+// expectation.
+type UnexpectedCallError struct {
+	DoubleName string
+	MethodName string
+	Args       []interface{}
+
+	// Candidates are the interactions the call has been matched against, in
+	// order of precedence.
+	Candidates []Interaction
+
+	// NegativeExpectation is the negative expectation matched by the call, if
+	// any.
+	NegativeExpectation Interaction
+
+	// Stateful is true when the double has interactions scoped to a state, or
+	// has left its initial state. State is then the state of the double when
+	// the call was received, empty for the initial state, and AllowedCalls
+	// are the interactions that could match calls in it.
+	Stateful     bool
+	State        string
+	AllowedCalls []Interaction
+}
+
+func (e *UnexpectedCallError) Error() string {
+	if e.NegativeExpectation != nil {
+		return fmt.Sprintf(
+			"Unexpected interaction: %s (expected not to receive %s)",
+			formatMethodCall(e.MethodName, e.Args),
+			e.NegativeExpectation,
+		)
+	}
+
+	if e.Stateful || e.State != "" {
+		return fmt.Sprintf(
+			"Unexpected interaction: %s (%s, %s)",
+			formatMethodCall(e.MethodName, e.Args),
+			formatState(e.State),
+			formatAllowedCalls(e.AllowedCalls),
+		)
+	}
+
+	return fmt.Sprintf("Unexpected interaction: %s", formatMethodCall(e.MethodName, e.Args))
+}
+
+func formatState(state string) string {
+	if state == "" {
+		return "in the initial state"
+	}
+
+	return fmt.Sprintf("in state '%s'", state)
+}
+
+func formatAllowedCalls(allowedCalls []Interaction) string {
+	if len(allowedCalls) == 0 {
+		return "no calls allowed"
+	}
+
+	formattedCalls := []string{}
+	for _, allowedCall := range allowedCalls {
+		formattedCalls = append(formattedCalls, fmt.Sprint(allowedCall))
+	}
+
+	return "allowed calls: " + strings.Join(formattedCalls, ", ")
+}
+
+// InvalidInteractionError is returned when an interaction doesn't match the
+// type of the double it is configured on.
+type InvalidInteractionError struct {
+	DoubleName  string
+	MethodName  string
+	Interaction Interaction
+	Reason      string
+}
+
+func newInvalidInteractionError(methodName string, format string, args ...interface{}) *InvalidInteractionError {
+	return &InvalidInteractionError{MethodName: methodName, Reason: fmt.Sprintf(format, args...)}
+}
+
+func (e *InvalidInteractionError) Error() string {
+	return fmt.Sprintf("Invalid interaction: %s", e.Reason)
+}
+
+// UnmetExpectationError is returned when verifying an expected interaction
+// that hasn't been matched by any call, or by fewer calls than it has been
+// limited to with `Times` or `Once`. MatchedCalls are the calls it has
+// matched, if any. ExpectedMatches is 0 when the interaction isn't limited.
+type UnmetExpectationError struct {
+	DoubleName      string
+	MethodName      string
+	Interaction     Interaction
+	Matches         int
+	MatchedCalls    []Call
+	ExpectedMatches int
+}
+
+func (e *UnmetExpectationError) Error() string {
+	if e.ExpectedMatches > 0 {
+		return fmt.Sprintf("Expected interaction: %s (matched %d)", e.Interaction, e.Matches)
+	}
+
+	return fmt.Sprintf("Expected interaction: %s", e.Interaction)
+}
+
+// UnusedAllowanceError is returned when verifying a double reporting unused
+// allowances, if an allowed interaction hasn't been matched by any call.
+type UnusedAllowanceError struct {
+	DoubleName  string
+	MethodName  string
+	Interaction Interaction
+}
+
+func (e *UnusedAllowanceError) Error() string {
+	return fmt.Sprintf("Unused allowance: %s", e.Interaction)
+}
+
+// UnverifiedCallsError is returned by `VerifyNoMoreInteractions` when some of
+// the calls received by a double haven't been matched by any expectation.
+// DroppedCalls is the number of unverified calls that have been dropped from
+// the call log, and are therefore missing from Calls.
+type UnverifiedCallsError struct {
+	DoubleName   string
+	Calls        []Call
+	DroppedCalls int
+}
+
+func (e *UnverifiedCallsError) Error() string {
+	formattedCalls := []string{}
+	for _, call := range e.Calls {
+		formattedCalls = append(formattedCalls, formatMethodCall(call.MethodName, call.Args))
+	}
+
+	if e.DroppedCalls > 0 {
+		formattedCalls = append(formattedCalls, fmt.Sprintf("%d more dropped from the call log", e.DroppedCalls))
+	}
+
+	return fmt.Sprintf("Unverified interactions: %s", strings.Join(formattedCalls, ", "))
+}
+
+// VerificationTimeoutError is returned by `VerifyInteractionsWithin` when the
+// interactions configured on a double haven't been satisfied within the
+// timeout. It wraps the last verification error.
+type VerificationTimeoutError struct {
+	DoubleName string
+	Timeout    time.Duration
+	Err        error
+}
+
+func (e *VerificationTimeoutError) Error() string {
+	return fmt.Sprintf("%s (timed out after %s)", e.Err, e.Timeout)
+}
+
+func (e *VerificationTimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// ArgumentError is returned when an interaction can't set or return an
+// argument of a call it has matched.
+type ArgumentError struct {
+	DoubleName string
+	MethodName string
+	Args       []interface{}
+
+	// Index is the zero-based index of the argument, and Action is either
+	// "set" or "return".
+	Index  int
+	Action string
+	Reason string
+}
+
+func (e *ArgumentError) Error() string {
+	return fmt.Sprintf("Cannot %s argument %d of %s: %s", e.Action, e.Index+1, formatMethodCall(e.MethodName, e.Args), e.Reason)
+}
+
+// withDoubleName returns a copy of err carrying the name of the double, if
+// err is one of the Moka error types.
+func withDoubleName(err error, doubleName string) error {
+	switch typedErr := err.(type) {
+	case *UnexpectedCallError:
+		namedErr := *typedErr
+		namedErr.DoubleName = doubleName
+		return &namedErr
+	case *UnmetExpectationError:
+		namedErr := *typedErr
+		namedErr.DoubleName = doubleName
+		return &namedErr
+	case *UnusedAllowanceError:
+		namedErr := *typedErr
+		namedErr.DoubleName = doubleName
+		return &namedErr
+	case *ArgumentError:
+		namedErr := *typedErr
+		namedErr.DoubleName = doubleName
+		return &namedErr
+	}
+
+	return err
+}
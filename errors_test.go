@@ -0,0 +1,111 @@
+package moka
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Errors", func() {
+	Describe("UnexpectedCallError", func() {
+		It("describes the unexpected call", func() {
+			err := &UnexpectedCallError{MethodName: "UltimateQuestion", Args: []interface{}{"life"}}
+
+			Expect(err).To(MatchError("Unexpected interaction: UltimateQuestion(\"life\")"))
+		})
+
+		Context("when the call matched a negative expectation", func() {
+			It("also describes the negative expectation", func() {
+				err := &UnexpectedCallError{
+					MethodName:          "UltimateQuestion",
+					Args:                []interface{}{"life"},
+					NegativeExpectation: newArgsInteraction("UltimateQuestion", []interface{}{"life"}, nil),
+				}
+
+				Expect(err).To(MatchError("Unexpected interaction: UltimateQuestion(\"life\") (expected not to receive UltimateQuestion(\"life\"))"))
+			})
+		})
+	})
+
+	Describe("InvalidInteractionError", func() {
+		It("describes the reason why the interaction is invalid", func() {
+			err := newInvalidInteractionError("UltimateQuestion", "type '%s' has no method '%s'", "DeepThought", "UltimateQuestion")
+
+			Expect(err.MethodName).To(Equal("UltimateQuestion"))
+			Expect(err).To(MatchError("Invalid interaction: type 'DeepThought' has no method 'UltimateQuestion'"))
+		})
+	})
+
+	Describe("UnmetExpectationError", func() {
+		It("describes the expected interaction", func() {
+			err := &UnmetExpectationError{
+				MethodName:  "UltimateQuestion",
+				Interaction: newArgsInteraction("UltimateQuestion", []interface{}{"life"}, nil),
+			}
+
+			Expect(err).To(MatchError("Expected interaction: UltimateQuestion(\"life\")"))
+		})
+
+		It("describes the number of matched calls when the interaction is limited", func() {
+			err := &UnmetExpectationError{
+				MethodName:      "UltimateQuestion",
+				Interaction:     newLimitedInteraction(newArgsInteraction("UltimateQuestion", []interface{}{"life"}, nil), 3),
+				Matches:         1,
+				ExpectedMatches: 3,
+			}
+
+			Expect(err).To(MatchError("Expected interaction: UltimateQuestion(\"life\") 3 times (matched 1)"))
+		})
+	})
+
+	Describe("UnusedAllowanceError", func() {
+		It("describes the unused allowance", func() {
+			err := &UnusedAllowanceError{
+				MethodName:  "UltimateQuestion",
+				Interaction: newArgsInteraction("UltimateQuestion", []interface{}{"life"}, nil),
+			}
+
+			Expect(err).To(MatchError("Unused allowance: UltimateQuestion(\"life\")"))
+		})
+	})
+
+	Describe("UnverifiedCallsError", func() {
+		It("describes all unverified calls", func() {
+			err := &UnverifiedCallsError{Calls: []Call{
+				{MethodName: "UltimateQuestion", Args: []interface{}{"life"}},
+				{MethodName: "UltimateAnswer"},
+			}}
+
+			Expect(err).To(MatchError("Unverified interactions: UltimateQuestion(\"life\"), UltimateAnswer()"))
+		})
+	})
+
+	Describe("VerificationTimeoutError", func() {
+		It("describes the verification error and the timeout", func() {
+			unmetExpectationError := &UnmetExpectationError{
+				MethodName:  "UltimateQuestion",
+				Interaction: newArgsInteraction("UltimateQuestion", []interface{}{"life"}, nil),
+			}
+			err := &VerificationTimeoutError{Timeout: time.Second, Err: unmetExpectationError}
+
+			Expect(err).To(MatchError("Expected interaction: UltimateQuestion(\"life\") (timed out after 1s)"))
+			Expect(errors.Unwrap(err)).To(Equal(unmetExpectationError))
+		})
+	})
+
+	Describe("ArgumentError", func() {
+		It("describes the argument and the reason", func() {
+			err := &ArgumentError{
+				MethodName: "UltimateAnswerInto",
+				Args:       []interface{}{0},
+				Index:      0,
+				Action:     "set",
+				Reason:     "'int' is not a pointer, a slice or a map",
+			}
+
+			Expect(err).To(MatchError("Cannot set argument 1 of UltimateAnswerInto(0): 'int' is not a pointer, a slice or a map"))
+		})
+	})
+})
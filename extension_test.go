@@ -0,0 +1,184 @@
+package moka_test
+
+import (
+	"fmt"
+	"reflect"
+
+	. "github.com/gcapizzi/moka"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Extending Moka", func() {
+	var double *StrictDouble
+
+	var failHandlerCalled bool
+	var failHandlerMessage string
+
+	failHandler := func(message string, _ ...int) {
+		failHandlerCalled = true
+		failHandlerMessage = message
+	}
+
+	BeforeEach(func() {
+		failHandlerCalled = false
+		failHandlerMessage = ""
+		RegisterDoublesFailHandler(failHandler)
+
+		double = NewStrictDouble()
+	})
+
+	It("supports custom interactions", func() {
+		AllowDouble(double).To(ReceiveCallsTo("Next").AndReturnInTurn("one", "two"))
+
+		Expect(double.Call("Next")).To(Equal([]interface{}{"one"}))
+		Expect(double.Call("Next")).To(Equal([]interface{}{"two"}))
+		Expect(double.Call("Next")).To(Equal([]interface{}{"one"}))
+		Expect(failHandlerCalled).To(BeFalse(), failHandlerMessage)
+
+		_, err := double.Call("Previous")
+
+		Expect(err).To(MatchError("Unexpected interaction: Previous()"))
+		Expect(failHandlerCalled).To(BeTrue())
+	})
+
+	It("supports custom interaction validators", func() {
+		double = NewStrictDoubleWithInteractionValidatorAndFailHandler(methodValidator{"Next"}, failHandler)
+
+		AllowDouble(double).To(ReceiveCallTo("Previous").AndReturn("zero"))
+
+		Expect(failHandlerCalled).To(BeTrue())
+		Expect(failHandlerMessage).To(Equal("Invalid interaction: method Previous is not allowed"))
+	})
+
+	It("supports custom doubles", func() {
+		countingDouble := &callCountingDouble{StrictDouble: double}
+
+		AllowDouble(countingDouble).To(ReceiveCallTo("Next").AndReturn("one"))
+		countingDouble.Call("Next")
+		countingDouble.Call("Next")
+
+		Expect(failHandlerCalled).To(BeFalse(), failHandlerMessage)
+		Expect(countingDouble.numberOfCalls).To(Equal(2))
+	})
+
+	It("supports custom doubles implementing only the Double interface", func() {
+		listDouble := &listDouble{}
+
+		AllowDouble(listDouble).To(ReceiveCallTo("Next").AndReturn("one"))
+		Expect(listDouble.Call("Next")).To(Equal([]interface{}{"one"}))
+		VerifyCalls(listDouble)
+
+		Expect(failHandlerCalled).To(BeFalse(), failHandlerMessage)
+
+		ResetDouble(listDouble)
+
+		Expect(failHandlerCalled).To(BeTrue())
+		Expect(failHandlerMessage).To(Equal("Double of type '*moka_test.listDouble' doesn't support ResetDouble"))
+
+		failHandlerCalled = false
+		err := MatchCallLogSnapshot(listDouble, "list")
+
+		Expect(err).To(MatchError("Double of type '*moka_test.listDouble' doesn't support MatchCallLogSnapshot"))
+		Expect(failHandlerCalled).To(BeTrue())
+	})
+})
+
+type cyclingInteractionBuilder struct {
+	methodName   string
+	returnValues []interface{}
+}
+
+func ReceiveCallsTo(methodName string) cyclingInteractionBuilder {
+	return cyclingInteractionBuilder{methodName: methodName}
+}
+
+func (b cyclingInteractionBuilder) AndReturnInTurn(returnValues ...interface{}) cyclingInteractionBuilder {
+	return cyclingInteractionBuilder{methodName: b.methodName, returnValues: returnValues}
+}
+
+func (b cyclingInteractionBuilder) Build() Interaction {
+	return &cyclingInteraction{methodName: b.methodName, returnValues: b.returnValues}
+}
+
+type cyclingInteraction struct {
+	methodName    string
+	returnValues  []interface{}
+	numberOfCalls int
+}
+
+func (i *cyclingInteraction) MethodName() string {
+	return i.methodName
+}
+
+func (i *cyclingInteraction) Call(methodName string, args []interface{}) ([]interface{}, bool, error) {
+	if methodName != i.methodName {
+		return nil, false, nil
+	}
+
+	returnValue := i.returnValues[i.numberOfCalls%len(i.returnValues)]
+	i.numberOfCalls++
+
+	return []interface{}{returnValue}, true, nil
+}
+
+func (i *cyclingInteraction) Verify() error {
+	return nil
+}
+
+func (i *cyclingInteraction) CheckType(t reflect.Type) error {
+	return nil
+}
+
+type methodValidator struct {
+	allowedMethodName string
+}
+
+func (v methodValidator) Validate(interaction Interaction) error {
+	if interaction.MethodName() != v.allowedMethodName {
+		return fmt.Errorf("Invalid interaction: method %s is not allowed", interaction.MethodName())
+	}
+
+	return nil
+}
+
+type listDouble struct {
+	interactions []Interaction
+}
+
+func (d *listDouble) AddInteraction(interaction Interaction) error {
+	d.interactions = append(d.interactions, interaction)
+	return nil
+}
+
+func (d *listDouble) Call(methodName string, args ...interface{}) ([]interface{}, error) {
+	for _, interaction := range d.interactions {
+		returnValues, matches, err := interaction.Call(methodName, args)
+		if matches {
+			return returnValues, err
+		}
+	}
+
+	return nil, fmt.Errorf("Unexpected interaction: %s", methodName)
+}
+
+func (d *listDouble) VerifyInteractions() error {
+	for _, interaction := range d.interactions {
+		err := interaction.Verify()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type callCountingDouble struct {
+	*StrictDouble
+	numberOfCalls int
+}
+
+func (d *callCountingDouble) Call(methodName string, args ...interface{}) ([]interface{}, error) {
+	d.numberOfCalls++
+	return d.StrictDouble.Call(methodName, args...)
+}
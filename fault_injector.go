@@ -0,0 +1,320 @@
+package moka
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// Fault is a failure injected by a `FaultInjector` into the calls to a
+// method.
+type Fault interface {
+	// Inject is invoked before each call to the method, with the call indexed
+	// among the calls to the same method, and a random number generator
+	// seeded by the injector. It returns the error to make the call fail
+	// with, or nil to let the call through. It may also block or panic.
+	Inject(call Call, random *rand.Rand) error
+}
+
+// FaultInjector is a double that wraps another double, injecting faults into
+// the calls to its methods before they reach the wrapped double. Injected
+// errors are returned in place of all return values of type `error`, with
+// zero values for all others, when the wrapped double is a typed
+// `StrictDouble`; otherwise, they are the only return value. Calls failed or
+// panicking because of a fault are still added to the call log of the wrapped
+// `StrictDouble`, without being matched against its interactions.
+type FaultInjector struct {
+	Double
+	faults        map[string][]Fault
+	numberOfCalls int
+	methodCalls   map[string]int
+	random        *rand.Rand
+	mutex         sync.Mutex
+}
+
+// NewFaultInjector instantiates a new `FaultInjector` wrapping the specified
+// double. The seed initialises the random number generator, so that
+// probabilistic faults are injected into the same calls on every run.
+func NewFaultInjector(double Double, seed int64) *FaultInjector {
+	return &FaultInjector{
+		Double:      double,
+		faults:      map[string][]Fault{},
+		methodCalls: map[string]int{},
+		random:      rand.New(&lockedSource{source: rand.NewSource(seed)}),
+	}
+}
+
+// Inject configures faults to be injected into the calls to a method, in
+// addition to the ones already configured. Faults are injected in order, up
+// to the first one returning an error. When the wrapped double is a typed
+// `StrictDouble`, faults that can make calls fail with an error are only
+// accepted for methods returning one; otherwise, the test fails and the
+// faults are not configured.
+func (f *FaultInjector) Inject(methodName string, faults ...Fault) error {
+	err := f.checkFaults(methodName, faults)
+	if err != nil {
+		globalFail(err.Error(), 2)
+		return err
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.faults[methodName] = append(f.faults[methodName], faults...)
+	return nil
+}
+
+// checkFaults rejects misconfigured faults, and validates faults against the
+// type of the wrapped double, if it is known.
+func (f *FaultInjector) checkFaults(methodName string, faults []Fault) error {
+	strictDouble, isStrict := lookupCapability[*StrictDouble](f.Double)
+
+	for _, fault := range faults {
+		if invalid, isInvalid := fault.(invalidFault); isInvalid {
+			err := newInvalidInteractionError(methodName, "cannot inject a fault into %s calls to %s, %s", invalid.calls, methodName, invalid.reason)
+			if isStrict {
+				return withDoubleName(err, strictDouble.name)
+			}
+
+			return err
+		}
+	}
+
+	if !isStrict || strictDouble.doubleType == nil {
+		return nil
+	}
+
+	method, err := lookupMethod(strictDouble.doubleType, methodName)
+	if err != nil {
+		return withDoubleName(err, strictDouble.name)
+	}
+
+	for _, fault := range faults {
+		checkedFault, isChecked := fault.(checkedFault)
+		if !isChecked {
+			continue
+		}
+
+		err := checkedFault.checkMethod(strictDouble.doubleType, method)
+		if err != nil {
+			return withDoubleName(err, strictDouble.name)
+		}
+	}
+
+	return nil
+}
+
+// Call injects the faults configured for the method, then delegates the call
+// to the wrapped double if none of them fails. If a custom fault fails a call
+// to a method that doesn't return an error, the test fails.
+func (f *FaultInjector) Call(methodName string, args ...interface{}) ([]interface{}, error) {
+	f.mutex.Lock()
+	faults := f.faults[methodName]
+	call := Call{MethodName: methodName, Args: args, Index: f.numberOfCalls, MethodIndex: f.methodCalls[methodName]}
+	f.numberOfCalls++
+	f.methodCalls[methodName]++
+	f.mutex.Unlock()
+
+	delegated := false
+	defer func() {
+		if !delegated {
+			f.recordUnhandledCall(methodName, args)
+		}
+	}()
+
+	for _, fault := range faults {
+		err := fault.Inject(call, f.random)
+		if err == nil {
+			continue
+		}
+
+		returnTypes := f.returnTypes(methodName)
+		if returnTypes != nil && !returnsError(returnTypes) {
+			err = fmt.Errorf("Cannot fail %s with an injected error: the method doesn't return an error", formatMethodCall(methodName, args))
+			globalFail(err.Error(), 2)
+			return nil, err
+		}
+
+		return errorReturnValues(returnTypes, err), nil
+	}
+
+	delegated = true
+	return f.Double.Call(methodName, args...)
+}
+
+// recordUnhandledCall adds a call that hasn't reached the wrapped double to
+// its call log, if it is a `StrictDouble`.
+func (f *FaultInjector) recordUnhandledCall(methodName string, args []interface{}) {
+	if strictDouble, isStrict := lookupCapability[*StrictDouble](f.Double); isStrict {
+		strictDouble.recordUnhandledCall(methodName, args)
+	}
+}
+
+// returnTypes returns the return types of a method of the wrapped double, or
+// nil if they are unknown.
+func (f *FaultInjector) returnTypes(methodName string) []reflect.Type {
+	strictDouble, isStrict := lookupCapability[*StrictDouble](f.Double)
+	if !isStrict || strictDouble.doubleType == nil {
+		return nil
+	}
+
+	method, err := lookupMethod(strictDouble.doubleType, methodName)
+	if err != nil {
+		return nil
+	}
+
+	return methodReturnTypes(method)
+}
+
+// lockedSource makes a random source safe for concurrent use.
+type lockedSource struct {
+	source rand.Source
+	mutex  sync.Mutex
+}
+
+func (s *lockedSource) Int63() int64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.source.Int63()
+}
+
+func (s *lockedSource) Seed(seed int64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.source.Seed(seed)
+}
+
+type faultFunc func(call Call, random *rand.Rand) error
+
+func (f faultFunc) Inject(call Call, random *rand.Rand) error {
+	return f(call, random)
+}
+
+// checkedFault is a fault that only applies to some methods, and is validated
+// against the method it is injected into on typed doubles.
+type checkedFault struct {
+	faultFunc
+	check func(t reflect.Type, method reflect.Method) error
+}
+
+func (f checkedFault) checkMethod(t reflect.Type, method reflect.Method) error {
+	return f.check(t, method)
+}
+
+// invalidFault is a misconfigured fault, rejected by `Inject` whatever the
+// wrapped double.
+type invalidFault struct {
+	calls  string
+	reason string
+}
+
+func (f invalidFault) Inject(call Call, random *rand.Rand) error {
+	return nil
+}
+
+// wrapFault returns a fault applying another fault, which is validated like
+// the wrapped one.
+func wrapFault(fault Fault, inject faultFunc) Fault {
+	if _, isInvalid := fault.(invalidFault); isInvalid {
+		return fault
+	}
+
+	if wrappedFault, isChecked := fault.(checkedFault); isChecked {
+		return checkedFault{faultFunc: inject, check: wrappedFault.check}
+	}
+
+	return inject
+}
+
+// checkReturnsError returns an error if the method doesn't return an error.
+func checkReturnsError(t reflect.Type, method reflect.Method) error {
+	if !returnsError(methodReturnTypes(method)) {
+		return newInvalidInteractionError(method.Name, "method '%s.%s' doesn't return an error", typeName(t), method.Name)
+	}
+
+	return nil
+}
+
+// FailWith makes every call fail with the specified error. On typed doubles,
+// it can only be injected into methods returning an error.
+func FailWith(err error) Fault {
+	return checkedFault{
+		faultFunc: func(call Call, random *rand.Rand) error {
+			return err
+		},
+		check: checkReturnsError,
+	}
+}
+
+// PanicWith makes every call panic with the specified value.
+func PanicWith(value interface{}) Fault {
+	return faultFunc(func(call Call, random *rand.Rand) error {
+		panic(value)
+	})
+}
+
+// Latency delays every call by the specified duration, according to the clock
+// registered with `RegisterDoublesClock`. If the first `context.Context`
+// argument is done first, the call fails with its error. On typed doubles, it
+// can only be injected into methods taking a context if they return an error.
+func Latency(duration time.Duration) Fault {
+	clock := globalClock
+	return checkedFault{
+		faultFunc: func(call Call, random *rand.Rand) error {
+			var done <-chan struct{}
+			ctx := contextArg(call.Args)
+			if ctx != nil {
+				done = ctx.Done()
+			}
+
+			select {
+			case <-clock.After(duration):
+				return nil
+			case <-done:
+				return ctx.Err()
+			}
+		},
+		check: func(t reflect.Type, method reflect.Method) error {
+			for _, argType := range methodArgTypes(method) {
+				if argType.Implements(contextType) {
+					return checkReturnsError(t, method)
+				}
+			}
+
+			return nil
+		},
+	}
+}
+
+// Every injects a fault into every nth call to a method only, starting from
+// the nth. The number of calls must be positive, or `Inject` will reject the
+// fault.
+func Every(n int, fault Fault) Fault {
+	if n <= 0 {
+		return invalidFault{calls: fmt.Sprintf("every %d", n), reason: "the number of calls must be positive"}
+	}
+
+	return wrapFault(fault, func(call Call, random *rand.Rand) error {
+		if (call.MethodIndex+1)%n != 0 {
+			return nil
+		}
+
+		return fault.Inject(call, random)
+	})
+}
+
+// WithProbability injects a fault into each call with the specified
+// probability, between 0 and 1.
+func WithProbability(probability float64, fault Fault) Fault {
+	return wrapFault(fault, func(call Call, random *rand.Rand) error {
+		if random.Float64() >= probability {
+			return nil
+		}
+
+		return fault.Inject(call, random)
+	})
+}
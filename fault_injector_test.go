@@ -0,0 +1,276 @@
+package moka
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FaultInjector", func() {
+	var double *StrictDouble
+	var injector *FaultInjector
+	var injectedErr error
+
+	BeforeEach(func() {
+		resetTestFail()
+		RegisterDoublesFailHandler(testFailHandler)
+		double = NewStrictDoubleWithTypeOf(myDeepThought{})
+		AllowDouble(double).To(ReceiveCallTo("RepeatQuestion").AndReturn("because", nil))
+		AllowDouble(double).To(ReceiveCallTo("UltimateAnswer").AndReturn(42))
+		injector = NewFaultInjector(double, 42)
+		injectedErr = errors.New("injected")
+	})
+
+	It("delegates calls without faults to the wrapped double", func() {
+		injector.Inject("UltimateQuestion", FailWith(injectedErr))
+
+		Expect(injector.Call("RepeatQuestion", "why?")).To(Equal([]interface{}{"because", nil}))
+		Expect(testFailHandlerInvoked).To(BeFalse(), testFailMessage)
+	})
+
+	It("returns injected errors in the error return slots", func() {
+		injector.Inject("RepeatQuestion", FailWith(injectedErr))
+
+		Expect(injector.Call("RepeatQuestion", "why?")).To(Equal([]interface{}{"", injectedErr}))
+	})
+
+	It("doesn't allow injecting errors into methods that don't return an error", func() {
+		err := injector.Inject("UltimateAnswer", Every(2, FailWith(injectedErr)))
+
+		Expect(err).To(MatchError("Invalid interaction: method 'myDeepThought.UltimateAnswer' doesn't return an error"))
+		Expect(testFailHandlerInvoked).To(BeTrue())
+		Expect(testFailMessage).To(Equal(err.Error()))
+
+		By("not configuring the faults", func() {
+			resetTestFail()
+
+			Expect(injector.Call("UltimateAnswer")).To(Equal([]interface{}{42}))
+			Expect(injector.Call("UltimateAnswer")).To(Equal([]interface{}{42}))
+			Expect(testFailHandlerInvoked).To(BeFalse(), testFailMessage)
+		})
+	})
+
+	It("doesn't allow injecting faults into methods the double doesn't have", func() {
+		err := injector.Inject("UltimateQuestin", PanicWith("boom"))
+
+		Expect(err).To(MatchError("Invalid interaction: type 'myDeepThought' has no method 'UltimateQuestin'"))
+		Expect(testFailHandlerInvoked).To(BeTrue())
+	})
+
+	It("allows injecting faults that don't fail calls into methods that don't return an error", func() {
+		Expect(injector.Inject("UltimateAnswer", PanicWith("boom"), Latency(0))).To(Succeed())
+		Expect(testFailHandlerInvoked).To(BeFalse(), testFailMessage)
+	})
+
+	It("fails when a custom fault fails a call to a method that doesn't return an error", func() {
+		injector.Inject("UltimateAnswer", faultFunc(func(call Call, random *rand.Rand) error {
+			return injectedErr
+		}))
+
+		_, err := injector.Call("UltimateAnswer")
+
+		Expect(err).To(MatchError("Cannot fail UltimateAnswer() with an injected error: the method doesn't return an error"))
+		Expect(testFailHandlerInvoked).To(BeTrue())
+		Expect(testFailMessage).To(Equal(err.Error()))
+	})
+
+	It("returns injected errors as the only return value for untyped doubles", func() {
+		untypedDouble := NewStrictDoubleWithInteractionValidatorAndFailHandler(NewNullInteractionValidator(), testFailHandler)
+		injector = NewFaultInjector(untypedDouble, 42)
+		injector.Inject("Query", FailWith(injectedErr))
+
+		Expect(injector.Call("Query", "arg")).To(Equal([]interface{}{injectedErr}))
+	})
+
+	It("records failed calls in the call log of the wrapped double without matching them", func() {
+		ExpectDouble(double).To(ReceiveCallTo("RepeatQuestion").AndReturn("because", nil))
+		injector.Inject("RepeatQuestion", FailWith(injectedErr))
+		injector.Call("RepeatQuestion", "why?")
+
+		Expect(double.Calls()).To(Equal([]Call{{MethodName: "RepeatQuestion", Args: []interface{}{"why?"}}}))
+		Expect(double.VerifyInteractions()).To(MatchError("Expected interaction: RepeatQuestion()"))
+		Expect(double.VerifyNoMoreInteractions()).To(MatchError("Unverified interactions: RepeatQuestion(\"why?\")"))
+	})
+
+	It("records panicking calls in the call log of the wrapped double", func() {
+		injector.Inject("RepeatQuestion", PanicWith("boom"))
+
+		func() {
+			defer func() {
+				recover()
+			}()
+			injector.Call("RepeatQuestion", "why?")
+		}()
+
+		Expect(double.Calls()).To(Equal([]Call{{MethodName: "RepeatQuestion", Args: []interface{}{"why?"}}}))
+	})
+
+	It("panics with injected panics", func() {
+		injector.Inject("RepeatQuestion", PanicWith("boom"))
+
+		var panicValue interface{}
+		func() {
+			defer func() {
+				panicValue = recover()
+			}()
+			injector.Call("RepeatQuestion", "why?")
+		}()
+
+		Expect(panicValue).To(Equal("boom"))
+	})
+
+	It("passes the index of the call among all calls and among the calls to the method to faults", func() {
+		var calls []Call
+		recordCall := faultFunc(func(call Call, random *rand.Rand) error {
+			calls = append(calls, call)
+			return nil
+		})
+		injector.Inject("UltimateAnswer", recordCall)
+		injector.Inject("RepeatQuestion", recordCall)
+
+		injector.Call("UltimateAnswer")
+		injector.Call("RepeatQuestion", "why?")
+		injector.Call("RepeatQuestion", "how?")
+
+		Expect(calls).To(Equal([]Call{
+			{MethodName: "UltimateAnswer", Args: nil, Index: 0, MethodIndex: 0},
+			{MethodName: "RepeatQuestion", Args: []interface{}{"why?"}, Index: 1, MethodIndex: 0},
+			{MethodName: "RepeatQuestion", Args: []interface{}{"how?"}, Index: 2, MethodIndex: 1},
+		}))
+	})
+
+	Describe("Every", func() {
+		It("requires a positive number of calls", func() {
+			err := injector.Inject("RepeatQuestion", Every(0, FailWith(injectedErr)))
+
+			Expect(err).To(MatchError("Invalid interaction: cannot inject a fault into every 0 calls to RepeatQuestion, the number of calls must be positive"))
+			Expect(testFailHandlerInvoked).To(BeTrue())
+			Expect(testFailMessage).To(Equal(err.Error()))
+
+			By("not configuring the faults", func() {
+				resetTestFail()
+
+				Expect(injector.Call("RepeatQuestion", "why?")).To(Equal([]interface{}{"because", nil}))
+				Expect(testFailHandlerInvoked).To(BeFalse(), testFailMessage)
+			})
+		})
+
+		It("requires a positive number of calls on untyped doubles too", func() {
+			injector = NewFaultInjector(NewStrictDouble(), 42)
+			err := injector.Inject("RepeatQuestion", WithProbability(1, Every(-1, PanicWith("boom"))))
+
+			Expect(err).To(MatchError("Invalid interaction: cannot inject a fault into every -1 calls to RepeatQuestion, the number of calls must be positive"))
+			Expect(testFailHandlerInvoked).To(BeTrue())
+		})
+
+		It("injects a fault into every nth call to the method", func() {
+			injector.Inject("RepeatQuestion", Every(3, FailWith(injectedErr)))
+
+			var errs []interface{}
+			for i := 0; i < 6; i++ {
+				returnValues, _ := injector.Call("RepeatQuestion", "why?")
+				errs = append(errs, returnValues[1])
+			}
+
+			Expect(errs).To(Equal([]interface{}{nil, nil, injectedErr, nil, nil, injectedErr}))
+		})
+
+		It("counts the calls to each method separately", func() {
+			injector.Inject("RepeatQuestion", Every(2, FailWith(injectedErr)))
+
+			injector.Call("UltimateAnswer")
+			Expect(injector.Call("RepeatQuestion", "why?")).To(Equal([]interface{}{"because", nil}))
+			Expect(injector.Call("RepeatQuestion", "why?")).To(Equal([]interface{}{"", injectedErr}))
+		})
+	})
+
+	Describe("WithProbability", func() {
+		failures := func(seed int64, probability float64) []bool {
+			injector := NewFaultInjector(double, seed)
+			injector.Inject("RepeatQuestion", WithProbability(probability, FailWith(injectedErr)))
+
+			var failed []bool
+			for i := 0; i < 100; i++ {
+				returnValues, _ := injector.Call("RepeatQuestion", "why?")
+				failed = append(failed, returnValues[1] != nil)
+			}
+
+			return failed
+		}
+
+		count := func(failed []bool) int {
+			n := 0
+			for _, f := range failed {
+				if f {
+					n++
+				}
+			}
+			return n
+		}
+
+		It("injects faults into the same calls for the same seed", func() {
+			Expect(failures(7, 0.5)).To(Equal(failures(7, 0.5)))
+			Expect(failures(7, 0.5)).NotTo(Equal(failures(8, 0.5)))
+		})
+
+		It("injects faults with the specified probability", func() {
+			Expect(count(failures(7, 0))).To(Equal(0))
+			Expect(count(failures(7, 1))).To(Equal(100))
+			Expect(count(failures(7, 0.3))).To(BeNumerically("~", 30, 15))
+		})
+	})
+
+	Describe("Latency", func() {
+		var clock *FakeClock
+
+		BeforeEach(func() {
+			clock = NewFakeClock()
+			RegisterDoublesClock(clock)
+		})
+
+		AfterEach(func() {
+			RegisterDoublesClock(nil)
+		})
+
+		It("delays calls until the clock advances", func() {
+			injector.Inject("RepeatQuestion", Latency(time.Minute))
+
+			results := make(chan []interface{}, 1)
+			go func() {
+				returnValues, _ := injector.Call("RepeatQuestion", "why?")
+				results <- returnValues
+			}()
+
+			clock.WaitForTimers(1)
+			Consistently(results).ShouldNot(Receive())
+
+			clock.Advance(time.Minute)
+			Eventually(results).Should(Receive(Equal([]interface{}{"because", nil})))
+		})
+
+		It("fails calls with the context error when the context is done first", func() {
+			fetcher := NewStrictDoubleWithTypeOf(myFetcher{})
+			injector = NewFaultInjector(fetcher, 42)
+			injector.Inject("Fetch", Latency(time.Minute))
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			Expect(injector.Call("Fetch", ctx, "key")).To(Equal([]interface{}{"", context.Canceled}))
+		})
+
+		It("can't be injected into methods taking a context but not returning an error", func() {
+			fetcher := NewStrictDoubleWithTypeOf(myFetcher{})
+			injector = NewFaultInjector(fetcher, 42)
+
+			err := injector.Inject("Cancel", Latency(time.Minute))
+
+			Expect(err).To(MatchError("Invalid interaction: method 'myFetcher.Cancel' doesn't return an error"))
+			Expect(testFailHandlerInvoked).To(BeTrue())
+		})
+	})
+})
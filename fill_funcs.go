@@ -0,0 +1,26 @@
+package moka
+
+import "reflect"
+
+// FillFuncs sets every nil exported function field of the struct pointed by
+// `structPtr` to a function routing its calls to the double, using the name
+// of the field as the method name. To validate the configured interactions
+// against the types of the fields, use a double instantiated with
+// `NewStrictDoubleWithTypeOf` on the struct.
+func FillFuncs(structPtr interface{}, double Double) {
+	structValue := reflect.ValueOf(structPtr)
+	if structValue.Kind() != reflect.Ptr || structValue.IsNil() || structValue.Elem().Kind() != reflect.Struct {
+		panic("You are trying to fill the function fields of '" + typeString(reflect.TypeOf(structPtr)) + "', which is not a pointer to a struct.")
+	}
+
+	structValue = structValue.Elem()
+	structType := structValue.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		fieldValue := structValue.Field(i)
+
+		if isFuncField(field) && fieldValue.IsNil() {
+			fieldValue.Set(makeFunc(field.Type, double, field.Name))
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package moka
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type storeOps struct {
+	Load   func(key string) (string, error)
+	Store  func(key string, value string) error
+	Delete func(key string) error
+	close  func()
+}
+
+var _ = Describe("FillFuncs", func() {
+	var ops storeOps
+	var double *StrictDouble
+
+	BeforeEach(func() {
+		resetTestFail()
+		RegisterDoublesFailHandler(testFailHandler)
+
+		ops = storeOps{Delete: func(string) error { return nil }}
+		double = NewStrictDoubleWithTypeOf(ops)
+		FillFuncs(&ops, double)
+	})
+
+	It("routes the calls to nil function fields to the double", func() {
+		AllowDouble(double).To(ReceiveCallTo("Load").With("key").AndReturn("value", nil))
+		AllowDouble(double).To(ReceiveCallTo("Store").With("key", "value").AndReturn(nil))
+
+		value, err := ops.Load("key")
+		Expect(value).To(Equal("value"))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(ops.Store("key", "value")).To(Succeed())
+
+		Expect(testFailHandlerInvoked).To(BeFalse(), testFailMessage)
+	})
+
+	It("leaves non-nil and unexported function fields alone", func() {
+		Expect(ops.Delete("key")).To(Succeed())
+		Expect(ops.close).To(BeNil())
+
+		Expect(testFailHandlerInvoked).To(BeFalse(), testFailMessage)
+	})
+
+	It("makes the test fail on unexpected calls", func() {
+		_, err := ops.Load("key")
+
+		Expect(testFailHandlerInvoked).To(BeTrue())
+		Expect(testFailMessage).To(Equal("Unexpected interaction: Load(\"key\")"))
+		Expect(err).To(MatchError("Unexpected interaction: Load(\"key\")"))
+	})
+
+	It("validates interactions against the types of the fields", func() {
+		AllowDouble(double).To(ReceiveCallTo("Load").With("key").AndReturn(42, nil))
+
+		Expect(testFailHandlerInvoked).To(BeTrue())
+		Expect(testFailMessage).To(Equal("Invalid interaction: type of return value 1 of method 'storeOps.Load' is 'string', 'int' given"))
+	})
+
+	It("doesn't treat unexported function fields as methods", func() {
+		AllowDouble(double).To(ReceiveCallTo("close"))
+
+		Expect(testFailHandlerInvoked).To(BeTrue())
+		Expect(testFailMessage).To(Equal("Invalid interaction: type 'storeOps' has no method 'close'"))
+	})
+
+	Context("when the double is untyped", func() {
+		var untypedDouble *StrictDouble
+
+		BeforeEach(func() {
+			ops = storeOps{}
+			untypedDouble = NewStrictDouble()
+			FillFuncs(&ops, untypedDouble)
+		})
+
+		It("makes the test fail when a return value has the wrong type", func() {
+			AllowDouble(untypedDouble).To(ReceiveCallTo("Load").With("key").AndReturn(42, nil))
+
+			value, err := ops.Load("key")
+
+			Expect(testFailHandlerInvoked).To(BeTrue())
+			Expect(testFailMessage).To(Equal("Cannot return from Load(\"key\"): type of return value 1 is 'string', 'int' given"))
+			Expect(value).To(BeEmpty())
+			Expect(err).To(MatchError(testFailMessage))
+		})
+
+		It("makes the test fail when a return value is missing", func() {
+			AllowDouble(untypedDouble).To(ReceiveCallTo("Load").With("key").AndReturn("value"))
+
+			_, err := ops.Load("key")
+
+			Expect(testFailHandlerInvoked).To(BeTrue())
+			Expect(testFailMessage).To(Equal("Cannot return from Load(\"key\"): return value 2 of type 'error' is missing"))
+			Expect(err).To(MatchError(testFailMessage))
+		})
+	})
+
+	Context("when the argument is not a pointer to a struct", func() {
+		It("panics", func() {
+			Expect(func() { FillFuncs(ops, double) }).To(Panic())
+		})
+	})
+})
@@ -0,0 +1,112 @@
+package moka
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// FuncMethodName is the method name used for the calls to a function double.
+const FuncMethodName = "Call"
+
+// FuncDouble is a double for a function value. Calls to `Func` are routed to
+// the embedded `StrictDouble` as calls to the `FuncMethodName` method, and
+// interactions are validated against the function type.
+type FuncDouble[F any] struct {
+	*StrictDouble
+	Func F
+}
+
+// NewFuncDouble instantiates a new `FuncDouble` and assigns its function to
+// the variable pointed by `fn`.
+func NewFuncDouble[F any](fn *F) *FuncDouble[F] {
+	double := Func[F]()
+	*fn = double.Func
+	return double
+}
+
+// Func instantiates a new `FuncDouble` for functions of type `F`.
+func Func[F any]() *FuncDouble[F] {
+	funcType := reflect.TypeOf((*F)(nil)).Elem()
+	if funcType.Kind() != reflect.Func {
+		panic("You are trying to instantiate a function double, but '" + typeString(funcType) + "' is not a function type.")
+	}
+
+	double := NewStrictDoubleWithInteractionValidatorAndFailHandler(
+		NewTypeInteractionValidator(funcType),
+		globalFailHandler,
+	)
+	double.name = typeString(funcType)
+	double.doubleType = funcType
+
+	return &FuncDouble[F]{
+		StrictDouble: double,
+		Func:         makeFunc(funcType, double, FuncMethodName).Interface().(F),
+	}
+}
+
+// makeFunc builds a function of the specified type that routes its calls to
+// a double. If the call fails, or the double returns values that don't match
+// the return types, the function returns zero values, and the error in the
+// last return value if it is of type `error`.
+func makeFunc(funcType reflect.Type, double Double, methodName string) reflect.Value {
+	returnTypes := methodReturnTypes(reflect.Method{Type: funcType})
+
+	return reflect.MakeFunc(funcType, func(args []reflect.Value) []reflect.Value {
+		callArgs := valuesToInterfaces(args)
+		returnValues, err := double.Call(methodName, callArgs...)
+		if err == nil {
+			var values []reflect.Value
+			values, err = returnValuesToValues(returnValues, returnTypes)
+			if err == nil {
+				return values
+			}
+
+			err = fmt.Errorf("Cannot return from %s: %w", formatMethodCall(methodName, callArgs), err)
+			failThroughDouble(double, err.Error())
+		}
+
+		returnValues = zeroValues(returnTypes)
+		if len(returnTypes) > 0 && returnTypes[len(returnTypes)-1] == errorType {
+			returnValues[len(returnValues)-1] = err
+		}
+
+		values, _ := returnValuesToValues(returnValues, returnTypes)
+		return values
+	})
+}
+
+// returnValuesToValues converts return values to the specified types, using
+// zero values for nil ones. It returns an error if a return value is missing
+// or isn't assignable to its type.
+func returnValuesToValues(returnValues []interface{}, returnTypes []reflect.Type) ([]reflect.Value, error) {
+	values := []reflect.Value{}
+	for i, returnType := range returnTypes {
+		if i >= len(returnValues) {
+			return nil, fmt.Errorf("return value %d of type '%s' is missing", i+1, typeString(returnType))
+		}
+
+		if returnValues[i] == nil {
+			values = append(values, reflect.Zero(returnType))
+			continue
+		}
+
+		value := reflect.ValueOf(returnValues[i])
+		if !value.Type().AssignableTo(returnType) {
+			return nil, fmt.Errorf("type of return value %d is '%s', '%s' given", i+1, typeString(returnType), typeString(value.Type()))
+		}
+
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// failThroughDouble fails the test through the fail handler of the double, if
+// it is a `StrictDouble`, or through the global one otherwise.
+func failThroughDouble(double Double, message string) {
+	if strictDouble, isStrict := lookupCapability[*StrictDouble](double); isStrict {
+		strictDouble.fail(message)
+		return
+	}
+
+	globalFail(message, 2)
+}
@@ -0,0 +1,99 @@
+package moka
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FuncDouble", func() {
+	var fetch func(key string) (string, error)
+	var double *FuncDouble[func(string) (string, error)]
+
+	BeforeEach(func() {
+		resetTestFail()
+		RegisterDoublesFailHandler(testFailHandler)
+
+		double = NewFuncDouble(&fetch)
+	})
+
+	It("assigns a function routing calls to the double", func() {
+		AllowDouble(double).To(ReceiveCall().With("key").AndReturn("value", nil))
+
+		value, err := fetch("key")
+
+		Expect(testFailHandlerInvoked).To(BeFalse(), testFailMessage)
+		Expect(value).To(Equal("value"))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("converts nil return values to zero values", func() {
+		AllowDouble(double).To(ReceiveCall().With("key").AndReturn("", nil))
+
+		_, err := fetch("key")
+
+		Expect(err).To(BeNil())
+	})
+
+	It("supports the whole DSL", func() {
+		ExpectDouble(double).To(ReceiveCall().AndDo(func(key string) (string, error) {
+			return "", errors.New(key + " not found")
+		}))
+
+		_, err := fetch("key")
+
+		Expect(err).To(MatchError("key not found"))
+		VerifyCalls(double)
+		Expect(testFailHandlerInvoked).To(BeFalse(), testFailMessage)
+	})
+
+	Context("when the call is unexpected", func() {
+		It("makes the test fail, returning zero values and the error", func() {
+			value, err := fetch("key")
+
+			Expect(testFailHandlerInvoked).To(BeTrue())
+			Expect(testFailMessage).To(Equal("Unexpected interaction: Call(\"key\")"))
+			Expect(value).To(BeEmpty())
+			Expect(err).To(MatchError("Unexpected interaction: Call(\"key\")"))
+		})
+	})
+
+	Context("when the interaction doesn't match the function type", func() {
+		It("makes the test fail", func() {
+			AllowDouble(double).To(ReceiveCall().With(42).AndReturn("value", nil))
+
+			Expect(testFailHandlerInvoked).To(BeTrue())
+			Expect(testFailMessage).To(Equal("Invalid interaction: type of argument 1 of method 'func(string) (string, error).Call' is 'string', 'int' given"))
+		})
+	})
+
+	Context("when the return values don't match the function type", func() {
+		It("makes the test fail, returning zero values", func() {
+			count := Func[func() int64]()
+			AllowDouble(count).To(ReceiveCall().AndReturnFunc(func(call Call) []interface{} {
+				return []interface{}{5}
+			}))
+
+			Expect(count.Func()).To(BeZero())
+			Expect(testFailHandlerInvoked).To(BeTrue())
+			Expect(testFailMessage).To(Equal("Cannot return from Call(): type of return value 1 is 'int64', 'int' given"))
+		})
+	})
+
+	Context("when the function is variadic", func() {
+		It("passes the variadic arguments as a slice", func() {
+			join := Func[func(...string) string]()
+			AllowDouble(join).To(ReceiveCall().With([]string{"a", "b"}).AndReturn("a,b"))
+
+			Expect(join.Func("a", "b")).To(Equal("a,b"))
+			Expect(testFailHandlerInvoked).To(BeFalse(), testFailMessage)
+		})
+	})
+
+	Context("when the type is not a function type", func() {
+		It("panics", func() {
+			Expect(func() { Func[string]() }).To(Panic())
+		})
+	})
+})
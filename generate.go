@@ -0,0 +1,256 @@
+package moka
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// GenerateAllowances renders recorded calls as Go statements allowing the
+// same calls on the double held by the variable named `doubleName`, e.g.
+//
+//	AllowDouble(client).To(ReceiveCallTo("Get").With("key").AndReturn("value", nil))
+//
+// Values are rendered as Go literals, with pointers dereferenced. Errors of
+// unexported types are rendered as `errors.New` calls with the same message,
+// function, channel and context arguments as captors matching any value of
+// their type, and function and channel return values as `nil`. Values that
+// can't be rendered as literals, e.g. functions nested in other values or
+// cyclic data structures, make it return an error.
+//
+// Repeated calls with the same arguments are rendered together. When they
+// returned different values, an allowance limited with `Once()` or `Times()`
+// is rendered for each of them but the last, which is unlimited. As the most
+// recent allowance is matched first, these are rendered in reverse order, so
+// that the values are returned in the order they were recorded.
+func GenerateAllowances(doubleName string, calls []RecordedCall) (string, error) {
+	groups, err := groupAllowances(calls)
+	if err != nil {
+		return "", err
+	}
+
+	var source strings.Builder
+	for _, group := range groups {
+		for i := len(group.runs) - 1; i >= 0; i-- {
+			limit := ""
+			if i < len(group.runs)-1 {
+				limit = generateLimit(group.runs[i].times)
+			}
+
+			source.WriteString(fmt.Sprintf("AllowDouble(%s).To(%s%s%s)\n", doubleName, group.receive, group.runs[i].returnValues, limit))
+		}
+	}
+
+	return source.String(), nil
+}
+
+// allowanceGroup holds the rendered return values of the calls with the same
+// rendered method and arguments, in runs of consecutive identical ones.
+type allowanceGroup struct {
+	receive string
+	runs    []*returnValuesRun
+}
+
+type returnValuesRun struct {
+	returnValues string
+	times        int
+}
+
+func groupAllowances(calls []RecordedCall) ([]*allowanceGroup, error) {
+	groups := []*allowanceGroup{}
+
+	for _, call := range calls {
+		receive, err := generateReceive(call)
+		if err != nil {
+			return nil, fmt.Errorf("Cannot generate an allowance for a call to %s: %w", call.MethodName, err)
+		}
+
+		returnValues, err := generateReturnValues(call)
+		if err != nil {
+			return nil, fmt.Errorf("Cannot generate an allowance for a call to %s: %w", call.MethodName, err)
+		}
+
+		var matchingGroup *allowanceGroup
+		for _, group := range groups {
+			if group.receive == receive {
+				matchingGroup = group
+				break
+			}
+		}
+
+		if matchingGroup == nil {
+			matchingGroup = &allowanceGroup{receive: receive}
+			groups = append(groups, matchingGroup)
+		}
+
+		runs := matchingGroup.runs
+		if len(runs) > 0 && runs[len(runs)-1].returnValues == returnValues {
+			runs[len(runs)-1].times++
+			continue
+		}
+
+		matchingGroup.runs = append(runs, &returnValuesRun{returnValues: returnValues, times: 1})
+	}
+
+	return groups, nil
+}
+
+func generateReceive(call RecordedCall) (string, error) {
+	receive := fmt.Sprintf("ReceiveCallTo(%q)", call.MethodName)
+
+	if len(call.Args) > 0 {
+		args := []string{}
+		for i, arg := range call.Args {
+			generatedArg, err := generateArg(arg)
+			if err != nil {
+				return "", fmt.Errorf("argument %d: %w", i+1, err)
+			}
+			args = append(args, generatedArg)
+		}
+		receive += fmt.Sprintf(".With(%s)", strings.Join(args, ", "))
+	}
+
+	return receive, nil
+}
+
+func generateReturnValues(call RecordedCall) (string, error) {
+	if len(call.ReturnValues) == 0 {
+		return "", nil
+	}
+
+	returnValues := []string{}
+	for i, returnValue := range call.ReturnValues {
+		generatedReturnValue, err := generateReturnValue(returnValue)
+		if err != nil {
+			return "", fmt.Errorf("return value %d: %w", i+1, err)
+		}
+		returnValues = append(returnValues, generatedReturnValue)
+	}
+
+	return fmt.Sprintf(".AndReturn(%s)", strings.Join(returnValues, ", ")), nil
+}
+
+func generateLimit(times int) string {
+	if times == 1 {
+		return ".Once()"
+	}
+
+	return fmt.Sprintf(".Times(%d)", times)
+}
+
+func generateArg(arg interface{}) (string, error) {
+	if arg == nil {
+		return "nil", nil
+	}
+
+	argType := reflect.TypeOf(arg)
+	if argType.Implements(contextType) {
+		return "NewCaptor[context.Context]()", nil
+	}
+
+	if argType.Kind() == reflect.Func || argType.Kind() == reflect.Chan {
+		return fmt.Sprintf("NewCaptor[%s]()", argType), nil
+	}
+
+	return generateLiteral(arg)
+}
+
+func generateReturnValue(returnValue interface{}) (string, error) {
+	if returnValue == nil {
+		return "nil", nil
+	}
+
+	returnType := reflect.TypeOf(returnValue)
+	if returnType.Kind() == reflect.Func || returnType.Kind() == reflect.Chan {
+		return "nil", nil
+	}
+
+	return generateLiteral(returnValue)
+}
+
+// generateLiteral renders a value as a Go literal, like `formatValue` does for
+// snapshots.
+func generateLiteral(value interface{}) (string, error) {
+	return formatValue(reflect.ValueOf(value), literalFormatter{}, map[uintptr]bool{}, true)
+}
+
+// literalFormatter renders values as Go literals: errors of unexported types
+// as `errors.New` calls, and dynamic basic values converted to their type when
+// it isn't the default type of their literal. Values that have no literal,
+// like non-nil functions and channels, pointers to basic values and cycles,
+// are rejected.
+type literalFormatter struct{}
+
+func (literalFormatter) formatSpecial(value reflect.Value, dynamic bool) (string, bool, error) {
+	valueType := value.Type()
+
+	if dynamic && valueType.Kind() != reflect.Interface && value.CanInterface() {
+		if err, isError := value.Interface().(error); isError && !isExported(valueType) && !isNilPointer(value) {
+			return fmt.Sprintf("errors.New(%q)", err.Error()), true, nil
+		}
+	}
+
+	switch valueType.Kind() {
+	case reflect.Func, reflect.Chan:
+		if value.IsNil() {
+			return fmt.Sprintf("(%s)(nil)", valueType), true, nil
+		}
+
+		return "", true, fmt.Errorf("'%s' values can't be rendered as Go literals", typeString(valueType))
+	case reflect.UnsafePointer:
+		return "", true, fmt.Errorf("'%s' values can't be rendered as Go literals", typeString(valueType))
+	case reflect.Ptr:
+		if value.IsNil() {
+			return "", false, nil
+		}
+
+		switch valueType.Elem().Kind() {
+		case reflect.Struct, reflect.Slice, reflect.Array, reflect.Map:
+			return "", false, nil
+		}
+
+		return "", true, fmt.Errorf("'%s' values can't be rendered as Go literals, as they don't point to composite values", typeString(valueType))
+	}
+
+	if !dynamic {
+		return "", false, nil
+	}
+
+	switch valueType {
+	case reflect.TypeOf(false), reflect.TypeOf(0), reflect.TypeOf(0.0), reflect.TypeOf(""):
+		return "", false, nil
+	}
+
+	switch valueType.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return fmt.Sprintf("%s(%#v)", valueType, value), true, nil
+	}
+
+	return "", false, nil
+}
+
+func (literalFormatter) formatCycle(pointerType reflect.Type) (string, error) {
+	return "", fmt.Errorf("cycles through '%s' values can't be rendered as Go literals", typeString(pointerType))
+}
+
+func isNilPointer(value reflect.Value) bool {
+	return value.Kind() == reflect.Ptr && value.IsNil()
+}
+
+// isExported returns true if a type, or the type it points to, can be
+// referenced outside of its package.
+func isExported(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Name() == "" || t.PkgPath() == "" {
+		return true
+	}
+
+	return unicode.IsUpper([]rune(t.Name())[0])
+}
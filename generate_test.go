@@ -0,0 +1,177 @@
+package moka
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type unexportedError struct {
+	Code int
+}
+
+func (e unexportedError) Error() string {
+	return "unexported error"
+}
+
+type listItem struct {
+	Name string
+	Next *listItem
+}
+
+type ExportedError struct {
+	Code int
+}
+
+func (e ExportedError) Error() string {
+	return "exported error"
+}
+
+var _ = Describe("GenerateAllowances", func() {
+	BeforeEach(func() {
+		resetTestFail()
+		RegisterDoublesFailHandler(testFailHandler)
+	})
+
+	It("renders calls as allowances", func() {
+		source, err := GenerateAllowances("collaborator", []RecordedCall{
+			{MethodName: "Query", Args: []interface{}{"arg"}, ReturnValues: []interface{}{"ARG"}},
+			{MethodName: "Command", Args: []interface{}{"arg"}, ReturnValues: []interface{}{"", errors.New("failed")}},
+			{MethodName: "CommandWithNoReturnValues", Args: []interface{}{"arg"}, ReturnValues: []interface{}{}},
+			{MethodName: "Ping", Args: []interface{}{}, ReturnValues: []interface{}{true}},
+		})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(source).To(Equal(
+			`AllowDouble(collaborator).To(ReceiveCallTo("Query").With("arg").AndReturn("ARG"))
+AllowDouble(collaborator).To(ReceiveCallTo("Command").With("arg").AndReturn("", errors.New("failed")))
+AllowDouble(collaborator).To(ReceiveCallTo("CommandWithNoReturnValues").With("arg"))
+AllowDouble(collaborator).To(ReceiveCallTo("Ping").AndReturn(true))
+`))
+	})
+
+	It("renders identical calls once", func() {
+		call := RecordedCall{MethodName: "Query", Args: []interface{}{"arg"}, ReturnValues: []interface{}{"ARG"}}
+
+		Expect(GenerateAllowances("collaborator", []RecordedCall{call, call})).To(Equal(
+			`AllowDouble(collaborator).To(ReceiveCallTo("Query").With("arg").AndReturn("ARG"))
+`))
+	})
+
+	It("renders repeated calls returning different values as limited allowances, most recent first", func() {
+		call := func(returnValue string) RecordedCall {
+			return RecordedCall{MethodName: "Query", Args: []interface{}{"arg"}, ReturnValues: []interface{}{returnValue}}
+		}
+
+		Expect(GenerateAllowances("collaborator", []RecordedCall{call("first"), call("second"), call("second"), call("last")})).To(Equal(
+			`AllowDouble(collaborator).To(ReceiveCallTo("Query").With("arg").AndReturn("last"))
+AllowDouble(collaborator).To(ReceiveCallTo("Query").With("arg").AndReturn("second").Times(2))
+AllowDouble(collaborator).To(ReceiveCallTo("Query").With("arg").AndReturn("first").Once())
+`))
+
+		By("returning the values in the order they were recorded", func() {
+			collaborator := CollaboratorDouble{Double: NewStrictDoubleWithTypeOf(CollaboratorDouble{})}
+			AllowDouble(collaborator).To(ReceiveCallTo("Query").With("arg").AndReturn("last"))
+			AllowDouble(collaborator).To(ReceiveCallTo("Query").With("arg").AndReturn("second").Times(2))
+			AllowDouble(collaborator).To(ReceiveCallTo("Query").With("arg").AndReturn("first").Once())
+
+			Expect([]string{
+				collaborator.Query("arg"),
+				collaborator.Query("arg"),
+				collaborator.Query("arg"),
+				collaborator.Query("arg"),
+				collaborator.Query("arg"),
+			}).To(Equal([]string{"first", "second", "second", "last", "last"}))
+		})
+	})
+
+	It("renders values as Go literals of the right type", func() {
+		source, err := GenerateAllowances("collaborator", []RecordedCall{
+			{
+				MethodName:   "Method",
+				Args:         []interface{}{nil, int64(42), 3.14, time.Second, []string{"a"}, map[string]int{"a": 1}},
+				ReturnValues: []interface{}{nil, (*int)(nil), ExportedError{Code: 1}, unexportedError{Code: 1}},
+			},
+		})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(source).To(Equal(
+			`AllowDouble(collaborator).To(ReceiveCallTo("Method")` +
+				`.With(nil, int64(42), 3.14, time.Duration(1000000000), []string{"a"}, map[string]int{"a":1})` +
+				`.AndReturn(nil, (*int)(nil), moka.ExportedError{Code:1}, errors.New("unexported error")))
+`))
+	})
+
+	It("renders functions, channels and contexts as captors", func() {
+		source, err := GenerateAllowances("collaborator", []RecordedCall{
+			{
+				MethodName:   "Method",
+				Args:         []interface{}{context.Background(), func(string) {}, make(chan int)},
+				ReturnValues: []interface{}{func() {}, make(chan int)},
+			},
+		})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(source).To(Equal(
+			`AllowDouble(collaborator).To(ReceiveCallTo("Method")` +
+				`.With(NewCaptor[context.Context](), NewCaptor[func(string)](), NewCaptor[chan int]())` +
+				`.AndReturn(nil, nil))
+`))
+	})
+
+	It("dereferences nested pointers", func() {
+		source, err := GenerateAllowances("collaborator", []RecordedCall{
+			{
+				MethodName:   "Method",
+				Args:         []interface{}{&listItem{Name: "first", Next: &listItem{Name: "second"}}},
+				ReturnValues: []interface{}{[]*listItem{{Name: "only"}}},
+			},
+		})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(source).To(Equal(
+			`AllowDouble(collaborator).To(ReceiveCallTo("Method")` +
+				`.With(&moka.listItem{Name:"first", Next:&moka.listItem{Name:"second", Next:(*moka.listItem)(nil)}})` +
+				`.AndReturn([]*moka.listItem{&moka.listItem{Name:"only", Next:(*moka.listItem)(nil)}}))
+`))
+	})
+
+	Context("when a value can't be rendered as a Go literal", func() {
+		It("returns an error", func() {
+			cycle := &listItem{Name: "cycle"}
+			cycle.Next = cycle
+
+			_, err := GenerateAllowances("collaborator", []RecordedCall{
+				{MethodName: "Method", Args: []interface{}{cycle}},
+			})
+			Expect(err).To(MatchError("Cannot generate an allowance for a call to Method: argument 1: cycles through '*moka.listItem' values can't be rendered as Go literals"))
+
+			_, err = GenerateAllowances("collaborator", []RecordedCall{
+				{MethodName: "Method", ReturnValues: []interface{}{struct{ Callback func() }{func() {}}}},
+			})
+			Expect(err).To(MatchError("Cannot generate an allowance for a call to Method: return value 1: 'func()' values can't be rendered as Go literals"))
+
+			answer := 42
+			_, err = GenerateAllowances("collaborator", []RecordedCall{
+				{MethodName: "Method", Args: []interface{}{&answer}},
+			})
+			Expect(err).To(MatchError("Cannot generate an allowance for a call to Method: argument 1: '*int' values can't be rendered as Go literals, as they don't point to composite values"))
+		})
+	})
+
+	It("renders the calls recorded by a recorder", func() {
+		recorder := NewRecorder(&realCollaborator{})
+		collaborator := CollaboratorDouble{Double: recorder}
+
+		collaborator.Query("arg")
+		collaborator.Command("")
+
+		Expect(GenerateAllowances("collaborator", recorder.RecordedCalls())).To(Equal(
+			`AllowDouble(collaborator).To(ReceiveCallTo("Query").With("arg").AndReturn("ARG"))
+AllowDouble(collaborator).To(ReceiveCallTo("Command").With("").AndReturn("", errors.New("empty command")))
+`))
+	})
+})
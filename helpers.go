@@ -6,10 +6,14 @@ import (
 )
 
 func formatMethodCall(methodName string, args []interface{}) string {
-	stringArgs := []string{}
-	for _, arg := range args {
-		stringArgs = append(stringArgs, fmt.Sprintf("%#v", arg))
+	return fmt.Sprintf("%s(%s)", methodName, formatValues(args))
+}
+
+func formatValues(values []interface{}) string {
+	stringValues := []string{}
+	for _, value := range values {
+		stringValues = append(stringValues, fmt.Sprintf("%#v", value))
 	}
 
-	return fmt.Sprintf("%s(%s)", methodName, strings.Join(stringArgs, ", "))
+	return strings.Join(stringValues, ", ")
 }
@@ -3,12 +3,29 @@ package moka
 import (
 	"fmt"
 	"reflect"
+	"runtime/debug"
+	"sync"
 )
 
-type interaction interface {
-	call(methodName string, args []interface{}) ([]interface{}, bool)
-	verify() error
-	checkType(t reflect.Type) error
+// Interaction is the interface implemented by everything that can be
+// configured on a `Double`. Implement it to add custom interaction types.
+type Interaction interface {
+	// MethodName returns the name of the method the interaction applies to.
+	MethodName() string
+
+	// Call is invoked by the double for every call it receives. It returns
+	// whether the call matches the interaction and, if it does, the values to
+	// return or an error that will make the test fail.
+	Call(methodName string, args []interface{}) ([]interface{}, bool, error)
+
+	// Verify returns an error if the interaction expected calls it hasn't
+	// received.
+	Verify() error
+
+	// CheckType returns an error if the interaction doesn't match the
+	// specified type, e.g. because the method doesn't exist or the arguments
+	// or return values have the wrong types.
+	CheckType(t reflect.Type) error
 }
 
 type argsInteraction struct {
@@ -21,18 +38,31 @@ func newArgsInteraction(methodName string, args []interface{}, returnValues []in
 	return argsInteraction{methodName: methodName, args: args, returnValues: returnValues}
 }
 
-func (i argsInteraction) call(methodName string, args []interface{}) ([]interface{}, bool) {
-	methodNamesAreEqual := i.methodName == methodName
-	argsAreEqual := i.args == nil || reflect.DeepEqual(i.args, args)
+func (i argsInteraction) MethodName() string {
+	return i.methodName
+}
 
-	if methodNamesAreEqual && argsAreEqual {
-		return i.returnValues, true
+func (i argsInteraction) literalArgs() ([]interface{}, bool) {
+	return i.args, i.args != nil
+}
+
+func (i argsInteraction) stubbedCall() (RecordedCall, bool) {
+	if i.args == nil || hasArgumentMatchers(i.args) {
+		return RecordedCall{}, false
 	}
 
-	return nil, false
+	return RecordedCall{MethodName: i.methodName, Args: i.args, ReturnValues: i.returnValues}, true
+}
+
+func (i argsInteraction) Call(methodName string, args []interface{}) ([]interface{}, bool, error) {
+	if callMatches(i.methodName, i.args, methodName, args) {
+		return i.returnValues, true, nil
+	}
+
+	return nil, false, nil
 }
 
-func (i argsInteraction) verify() error {
+func (i argsInteraction) Verify() error {
 	return nil
 }
 
@@ -40,64 +70,215 @@ func (i argsInteraction) String() string {
 	return formatMethodCall(i.methodName, i.args)
 }
 
-func (i argsInteraction) checkType(t reflect.Type) error {
-	method, methodExists := t.MethodByName(i.methodName)
+func (i argsInteraction) CheckType(t reflect.Type) error {
+	method, err := lookupMethod(t, i.methodName)
+	if err != nil {
+		return err
+	}
 
-	if !methodExists {
-		return fmt.Errorf("Invalid interaction: type '%s' has no method '%s'", t.Name(), i.methodName)
+	err = checkArgs(t, method, i.args)
+	if err != nil {
+		return err
 	}
 
-	if i.args != nil {
-		expectedArgTypes := methodArgTypes(t, method)
+	return checkReturnValues(t, method, i.returnValues)
+}
+
+// argumentMatcher can be used in place of an expected argument to match
+// actual arguments by something other than equality.
+type argumentMatcher interface {
+	matches(arg interface{}) bool
+	matched(arg interface{})
+	matchedType() reflect.Type
+}
 
-		expectedNumberOfArgs := len(expectedArgTypes)
-		numberOfArgs := len(i.args)
-		if expectedNumberOfArgs != numberOfArgs {
-			return fmt.Errorf(
-				"Invalid interaction: method '%s.%s' takes %d arguments, %d specified",
-				t.Name(),
-				method.Name,
-				expectedNumberOfArgs,
-				numberOfArgs,
-			)
+func callMatches(expectedMethodName string, expectedArgs []interface{}, methodName string, args []interface{}) bool {
+	methodNamesAreEqual := expectedMethodName == methodName
+	argsAreEqual := expectedArgs == nil || argsMatch(expectedArgs, args)
+
+	if methodNamesAreEqual && argsAreEqual {
+		notifyArgumentMatchers(expectedArgs, args)
+		return true
+	}
+
+	return false
+}
+
+func argsMatch(expectedArgs []interface{}, args []interface{}) bool {
+	if len(expectedArgs) != len(args) {
+		return false
+	}
+
+	for i, expectedArg := range expectedArgs {
+		if matcher, isMatcher := expectedArg.(argumentMatcher); isMatcher {
+			if !matcher.matches(args[i]) {
+				return false
+			}
+		} else if !reflect.DeepEqual(expectedArg, args[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func hasArgumentMatchers(args []interface{}) bool {
+	for _, arg := range args {
+		if _, isMatcher := arg.(argumentMatcher); isMatcher {
+			return true
+		}
+	}
+
+	return false
+}
+
+func notifyArgumentMatchers(expectedArgs []interface{}, args []interface{}) {
+	for i, expectedArg := range expectedArgs {
+		if matcher, isMatcher := expectedArg.(argumentMatcher); isMatcher {
+			matcher.matched(args[i])
+		}
+	}
+}
+
+// lookupMethod looks up a method of a type. The type of the returned method
+// doesn't include the receiver. Function types are treated as having a single
+// method, named `FuncMethodName`, while the function fields of struct types
+// are treated as methods.
+func lookupMethod(t reflect.Type, methodName string) (reflect.Method, error) {
+	if t.Kind() == reflect.Func && methodName == FuncMethodName {
+		return reflect.Method{Name: methodName, Type: t}, nil
+	}
+
+	method, methodExists := t.MethodByName(methodName)
+
+	if !methodExists {
+		funcField, funcFieldExists := lookupFuncField(t, methodName)
+		if funcFieldExists {
+			return reflect.Method{Name: methodName, Type: funcField.Type}, nil
 		}
 
-		for i, arg := range i.args {
-			argType := reflect.TypeOf(arg)
-			expectedType := expectedArgTypes[i]
-			if !assignable(argType, expectedType) {
-				return fmt.Errorf(
-					"Invalid interaction: type of argument %d of method '%s.%s' is '%s', '%s' given",
+		return method, newInvalidInteractionError(methodName, "type '%s' has no method '%s'", typeName(t), methodName)
+	}
+
+	if t.Kind() != reflect.Interface {
+		method.Type = withoutReceiver(method.Type)
+	}
+
+	return method, nil
+}
+
+func lookupFuncField(t reflect.Type, name string) (reflect.StructField, bool) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return reflect.StructField{}, false
+	}
+
+	field, fieldExists := t.FieldByName(name)
+	if !fieldExists || !isFuncField(field) {
+		return reflect.StructField{}, false
+	}
+
+	return field, true
+}
+
+// isFuncField returns whether a field is an exported function field declared
+// directly in its struct.
+func isFuncField(field reflect.StructField) bool {
+	return field.IsExported() && len(field.Index) == 1 && field.Type.Kind() == reflect.Func
+}
+
+func withoutReceiver(methodType reflect.Type) reflect.Type {
+	argTypes := []reflect.Type{}
+	for i := 1; i < methodType.NumIn(); i++ {
+		argTypes = append(argTypes, methodType.In(i))
+	}
+
+	return reflect.FuncOf(argTypes, methodReturnTypes(reflect.Method{Type: methodType}), methodType.IsVariadic())
+}
+
+func checkArgs(t reflect.Type, method reflect.Method, args []interface{}) error {
+	if args == nil {
+		return nil
+	}
+
+	expectedArgTypes := methodArgTypes(method)
+
+	expectedNumberOfArgs := len(expectedArgTypes)
+	numberOfArgs := len(args)
+	if expectedNumberOfArgs != numberOfArgs {
+		return newInvalidInteractionError(
+			method.Name,
+			"method '%s.%s' takes %d arguments, %d specified",
+			typeName(t),
+			method.Name,
+			expectedNumberOfArgs,
+			numberOfArgs,
+		)
+	}
+
+	for i, arg := range args {
+		expectedType := expectedArgTypes[i]
+
+		if matcher, isMatcher := arg.(argumentMatcher); isMatcher {
+			matchedType := matcher.matchedType()
+			if !matchedType.AssignableTo(expectedType) && !expectedType.AssignableTo(matchedType) {
+				return newInvalidInteractionError(
+					method.Name,
+					"type of argument %d of method '%s.%s' is '%s', matcher for '%s' given",
 					i+1,
-					t.Name(),
+					typeName(t),
 					method.Name,
 					typeString(expectedType),
-					typeString(argType),
+					typeString(matchedType),
 				)
 			}
+
+			continue
+		}
+
+		argType := reflect.TypeOf(arg)
+		if !assignable(argType, expectedType) {
+			return newInvalidInteractionError(
+				method.Name,
+				"type of argument %d of method '%s.%s' is '%s', '%s' given",
+				i+1,
+				typeName(t),
+				method.Name,
+				typeString(expectedType),
+				typeString(argType),
+			)
 		}
 	}
 
+	return nil
+}
+
+func checkReturnValues(t reflect.Type, method reflect.Method, returnValues []interface{}) error {
 	expectedNumberOfReturnValues := method.Type.NumOut()
-	numberOfReturnValues := len(i.returnValues)
+	numberOfReturnValues := len(returnValues)
 	if numberOfReturnValues != expectedNumberOfReturnValues {
-		return fmt.Errorf(
-			"Invalid interaction: method '%s.%s' returns %d values, %d specified",
-			t.Name(),
+		return newInvalidInteractionError(
+			method.Name,
+			"method '%s.%s' returns %d values, %d specified",
+			typeName(t),
 			method.Name,
 			expectedNumberOfReturnValues,
 			numberOfReturnValues,
 		)
 	}
 
-	for i, returnValue := range i.returnValues {
+	for i, returnValue := range returnValues {
 		returnValueType := reflect.TypeOf(returnValue)
 		expectedType := method.Type.Out(i)
 		if !assignable(returnValueType, expectedType) {
-			return fmt.Errorf(
-				"Invalid interaction: type of return value %d of method '%s.%s' is '%s', '%s' given",
+			return newInvalidInteractionError(
+				method.Name,
+				"type of return value %d of method '%s.%s' is '%s', '%s' given",
 				i+1,
-				t.Name(),
+				typeName(t),
 				method.Name,
 				typeString(expectedType),
 				typeString(returnValueType),
@@ -108,6 +289,170 @@ func (i argsInteraction) checkType(t reflect.Type) error {
 	return nil
 }
 
+type panicInteraction struct {
+	methodName string
+	args       []interface{}
+	value      interface{}
+}
+
+func newPanicInteraction(methodName string, args []interface{}, value interface{}) panicInteraction {
+	return panicInteraction{methodName: methodName, args: args, value: value}
+}
+
+func (i panicInteraction) MethodName() string {
+	return i.methodName
+}
+
+func (i panicInteraction) literalArgs() ([]interface{}, bool) {
+	return i.args, i.args != nil
+}
+
+func (i panicInteraction) Call(methodName string, args []interface{}) ([]interface{}, bool, error) {
+	if callMatches(i.methodName, i.args, methodName, args) {
+		return nil, true, interactionPanic{value: i.value}
+	}
+
+	return nil, false, nil
+}
+
+func (i panicInteraction) Verify() error {
+	return nil
+}
+
+func (i panicInteraction) String() string {
+	return formatMethodCall(i.methodName, i.args)
+}
+
+func (i panicInteraction) CheckType(t reflect.Type) error {
+	method, err := lookupMethod(t, i.methodName)
+	if err != nil {
+		return err
+	}
+
+	return checkArgs(t, method, i.args)
+}
+
+// interactionPanic is returned by interactions that need the double to panic
+// with the specified value, after all bookkeeping has been done.
+type interactionPanic struct {
+	value interface{}
+}
+
+func (p interactionPanic) Error() string {
+	return fmt.Sprintf("Interaction panicked: %v", p.value)
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+type returnErrorInteraction struct {
+	methodName  string
+	args        []interface{}
+	err         error
+	returnTypes []reflect.Type
+}
+
+func newReturnErrorInteraction(methodName string, args []interface{}, err error) *returnErrorInteraction {
+	return &returnErrorInteraction{methodName: methodName, args: args, err: err}
+}
+
+func (i *returnErrorInteraction) MethodName() string {
+	return i.methodName
+}
+
+func (i *returnErrorInteraction) literalArgs() ([]interface{}, bool) {
+	return i.args, i.args != nil
+}
+
+func (i *returnErrorInteraction) stubbedCall() (RecordedCall, bool) {
+	if i.args == nil || hasArgumentMatchers(i.args) || i.returnTypes == nil {
+		return RecordedCall{}, false
+	}
+
+	return RecordedCall{MethodName: i.methodName, Args: i.args, ReturnValues: errorReturnValues(i.returnTypes, i.err)}, true
+}
+
+func (i *returnErrorInteraction) Call(methodName string, args []interface{}) ([]interface{}, bool, error) {
+	if !callMatches(i.methodName, i.args, methodName, args) {
+		return nil, false, nil
+	}
+
+	return errorReturnValues(i.returnTypes, i.err), true, nil
+}
+
+// errorReturnValues returns `err` in all error return slots and zero values
+// in all other ones. If the return types are unknown, `err` is returned as the
+// only value.
+func errorReturnValues(returnTypes []reflect.Type, err error) []interface{} {
+	if returnTypes == nil {
+		return []interface{}{err}
+	}
+
+	returnValues := zeroValues(returnTypes)
+	for i, returnType := range returnTypes {
+		if returnType == errorType {
+			returnValues[i] = err
+		}
+	}
+
+	return returnValues
+}
+
+func returnsError(returnTypes []reflect.Type) bool {
+	for _, returnType := range returnTypes {
+		if returnType == errorType {
+			return true
+		}
+	}
+
+	return false
+}
+
+func zeroValues(types []reflect.Type) []interface{} {
+	values := []interface{}{}
+	for _, t := range types {
+		values = append(values, reflect.Zero(t).Interface())
+	}
+	return values
+}
+
+func methodReturnTypes(method reflect.Method) []reflect.Type {
+	returnTypes := []reflect.Type{}
+	for i := 0; i < method.Type.NumOut(); i++ {
+		returnTypes = append(returnTypes, method.Type.Out(i))
+	}
+	return returnTypes
+}
+
+func (i *returnErrorInteraction) Verify() error {
+	return nil
+}
+
+func (i *returnErrorInteraction) String() string {
+	return formatMethodCall(i.methodName, i.args)
+}
+
+// CheckType also records the return types of the method, so that all
+// non-error return values can be filled with zero values.
+func (i *returnErrorInteraction) CheckType(t reflect.Type) error {
+	method, err := lookupMethod(t, i.methodName)
+	if err != nil {
+		return err
+	}
+
+	err = checkArgs(t, method, i.args)
+	if err != nil {
+		return err
+	}
+
+	returnTypes := methodReturnTypes(method)
+	if !returnsError(returnTypes) {
+		return newInvalidInteractionError(method.Name, "method '%s.%s' doesn't return an error", typeName(t), method.Name)
+	}
+
+	i.returnTypes = returnTypes
+	return nil
+}
+
 type bodyInteraction struct {
 	methodName string
 	body       interface{}
@@ -117,26 +462,83 @@ func newBodyInteraction(methodName string, body interface{}) bodyInteraction {
 	return bodyInteraction{methodName: methodName, body: body}
 }
 
-func (i bodyInteraction) call(methodName string, args []interface{}) ([]interface{}, bool) {
+func (i bodyInteraction) MethodName() string {
+	return i.methodName
+}
+
+func (i bodyInteraction) literalArgs() ([]interface{}, bool) {
+	return nil, false
+}
+
+func (i bodyInteraction) Call(methodName string, args []interface{}) ([]interface{}, bool, error) {
 	if methodName == i.methodName {
-		bodyAsValue := reflect.ValueOf(i.body)
-		argsAsValues := interfacesToValues(args)
-		returnValuesAsValues := bodyAsValue.Call(argsAsValues)
-		returnValuesAsInterfaces := valuesToInterfaces(returnValuesAsValues)
-		return returnValuesAsInterfaces, true
+		returnValues, err := callRecovering(i, methodName, args, func() []interface{} {
+			bodyAsValue := reflect.ValueOf(i.body)
+			return callValue(bodyAsValue, interfacesToValues(args, bodyAsValue.Type()))
+		})
+
+		return returnValues, true, err
 	}
 
-	return nil, false
+	return nil, false, nil
 }
 
-func interfacesToValues(interfaces []interface{}) []reflect.Value {
+// callRecovering invokes a user-provided function, recovering any panic and
+// turning it into an error reporting the call, the interaction it belongs to,
+// if any, and the original stack trace.
+func callRecovering(interaction Interaction, methodName string, args []interface{}, f func() []interface{}) (returnValues []interface{}, err error) {
+	defer func() {
+		if value := recover(); value != nil {
+			returnValues = nil
+			description := formatMethodCall(methodName, args)
+			if interaction != nil {
+				description = fmt.Sprintf("%s (matched by %s)", description, interaction)
+			}
+			err = fmt.Errorf("Panic in interaction: %s\n%v\n\n%s", description, value, debug.Stack())
+		}
+	}()
+
+	return f(), nil
+}
+
+// interfacesToValues converts the arguments to `reflect.Value`s. `nil`
+// arguments are converted to zero values of the corresponding parameter type
+// of `funcType`, as `reflect.ValueOf(nil)` would not be a valid argument. For
+// variadic functions, the parameter type of the arguments at or after the
+// variadic position is the element type, unless the variadic arguments are
+// passed as a slice, as doubles do.
+func interfacesToValues(interfaces []interface{}, funcType reflect.Type) []reflect.Value {
+	variadicIndex := -1
+	if funcType.IsVariadic() && !passesVariadicSlice(funcType, interfaces) {
+		variadicIndex = funcType.NumIn() - 1
+	}
+
 	values := []reflect.Value{}
-	for _, i := range interfaces {
-		values = append(values, reflect.ValueOf(i))
+	for index, i := range interfaces {
+		switch {
+		case i != nil:
+			values = append(values, reflect.ValueOf(i))
+		case variadicIndex >= 0 && index >= variadicIndex:
+			values = append(values, reflect.Zero(funcType.In(variadicIndex).Elem()))
+		case index < funcType.NumIn():
+			values = append(values, reflect.Zero(funcType.In(index)))
+		default:
+			values = append(values, reflect.ValueOf(i))
+		}
 	}
 	return values
 }
 
+// passesVariadicSlice returns whether the arguments of a call to a variadic
+// function pass the variadic arguments as a single slice.
+func passesVariadicSlice(funcType reflect.Type, args []interface{}) bool {
+	if len(args) != funcType.NumIn() || args[len(args)-1] == nil {
+		return false
+	}
+
+	return reflect.TypeOf(args[len(args)-1]).AssignableTo(funcType.In(funcType.NumIn() - 1))
+}
+
 func valuesToInterfaces(values []reflect.Value) []interface{} {
 	interfaces := []interface{}{}
 	for _, v := range values {
@@ -145,26 +547,30 @@ func valuesToInterfaces(values []reflect.Value) []interface{} {
 	return interfaces
 }
 
-func (i bodyInteraction) verify() error {
+func (i bodyInteraction) Verify() error {
 	return nil
 }
 
-func (i bodyInteraction) checkType(t reflect.Type) error {
-	method, methodExists := t.MethodByName(i.methodName)
+func (i bodyInteraction) String() string {
+	return fmt.Sprintf("%s with body %s", i.methodName, typeString(reflect.TypeOf(i.body)))
+}
 
-	if !methodExists {
-		return fmt.Errorf("Invalid interaction: type '%s' has no method '%s'", t.Name(), i.methodName)
+func (i bodyInteraction) CheckType(t reflect.Type) error {
+	method, err := lookupMethod(t, i.methodName)
+	if err != nil {
+		return err
 	}
 
 	bodyType := reflect.TypeOf(i.body)
-	expectedArgTypes := methodArgTypes(t, method)
+	expectedArgTypes := methodArgTypes(method)
 
 	expectedNumberOfArgs := len(expectedArgTypes)
 	numberOfArgs := bodyType.NumIn()
 	if expectedNumberOfArgs != numberOfArgs {
-		return fmt.Errorf(
-			"Invalid interaction: method '%s.%s' takes %d arguments, provided func takes %d",
-			t.Name(),
+		return newInvalidInteractionError(
+			method.Name,
+			"method '%s.%s' takes %d arguments, provided func takes %d",
+			typeName(t),
 			method.Name,
 			expectedNumberOfArgs,
 			numberOfArgs,
@@ -174,10 +580,11 @@ func (i bodyInteraction) checkType(t reflect.Type) error {
 	for i, expectedType := range expectedArgTypes {
 		argType := bodyType.In(i)
 		if argType != expectedType {
-			return fmt.Errorf(
-				"Invalid interaction: type of argument %d of method '%s.%s' is '%s', type of argument %d of provided func is '%s'",
+			return newInvalidInteractionError(
+				method.Name,
+				"type of argument %d of method '%s.%s' is '%s', type of argument %d of provided func is '%s'",
 				i+1,
-				t.Name(),
+				typeName(t),
 				method.Name,
 				typeString(expectedType),
 				i+1,
@@ -189,9 +596,10 @@ func (i bodyInteraction) checkType(t reflect.Type) error {
 	expectedNumberOfReturnValues := method.Type.NumOut()
 	numberOfReturnValues := bodyType.NumOut()
 	if numberOfReturnValues != expectedNumberOfReturnValues {
-		return fmt.Errorf(
-			"Invalid interaction: method '%s.%s' returns %d values, provided func returns %d",
-			t.Name(),
+		return newInvalidInteractionError(
+			method.Name,
+			"method '%s.%s' returns %d values, provided func returns %d",
+			typeName(t),
 			method.Name,
 			expectedNumberOfReturnValues,
 			numberOfReturnValues,
@@ -202,10 +610,11 @@ func (i bodyInteraction) checkType(t reflect.Type) error {
 		returnValueType := bodyType.Out(i)
 		expectedType := method.Type.Out(i)
 		if returnValueType != expectedType {
-			return fmt.Errorf(
-				"Invalid interaction: type of return value %d of method '%s.%s' is '%s', type of return value %d of provided func is '%s'",
+			return newInvalidInteractionError(
+				method.Name,
+				"type of return value %d of method '%s.%s' is '%s', type of return value %d of provided func is '%s'",
 				i+1,
-				t.Name(),
+				typeName(t),
 				method.Name,
 				typeString(expectedType),
 				i+1,
@@ -217,33 +626,147 @@ func (i bodyInteraction) checkType(t reflect.Type) error {
 	return nil
 }
 
+// Expectation is an expected interaction configured on a double, as
+// returned by `ExpectationTarget.To`. It keeps the history of the calls it has
+// matched, so that they can be verified.
+type Expectation interface {
+	Interaction
+
+	// Matches returns the number of calls the expectation has matched.
+	Matches() int
+
+	// MatchedCalls returns the calls the expectation has matched, in order,
+	// indexed by `InteractionIndex`.
+	MatchedCalls() []Call
+}
+
+// expectedInteraction wraps an interaction, keeping the history of the calls
+// it has matched, so that they can be verified.
 type expectedInteraction struct {
-	interaction interaction
-	called      bool
+	interaction  Interaction
+	matchedCalls []Call
+	mutex        sync.Mutex
 }
 
-func newExpectedInteraction(interaction interaction) *expectedInteraction {
-	return &expectedInteraction{interaction: interaction}
+func newExpectedInteraction(interaction Interaction) *expectedInteraction {
+	return &expectedInteraction{interaction: interaction, matchedCalls: []Call{}}
 }
 
-func (i *expectedInteraction) call(methodName string, args []interface{}) ([]interface{}, bool) {
-	returnValues, matches := i.interaction.call(methodName, args)
-	i.called = matches
-	return returnValues, matches
+func (i *expectedInteraction) MethodName() string {
+	return i.interaction.MethodName()
 }
 
-func (i *expectedInteraction) verify() error {
-	if !i.called {
-		return fmt.Errorf("Expected interaction: %s", i.interaction)
+func (i *expectedInteraction) unwrap() Interaction {
+	return i.interaction
+}
+
+func (i *expectedInteraction) Call(methodName string, args []interface{}) ([]interface{}, bool, error) {
+	returnValues, matches, err := i.interaction.Call(methodName, args)
+
+	if matches {
+		i.mutex.Lock()
+		i.matchedCalls = append(i.matchedCalls, Call{MethodName: methodName, Args: args, InteractionIndex: len(i.matchedCalls)})
+		i.mutex.Unlock()
 	}
 
-	return nil
+	return returnValues, matches, err
+}
+
+func (i *expectedInteraction) Matches() int {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	return len(i.matchedCalls)
 }
 
-func (i *expectedInteraction) checkType(t reflect.Type) error {
+func (i *expectedInteraction) MatchedCalls() []Call {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	return append([]Call{}, i.matchedCalls...)
+}
+
+// Verify checks that the interaction has matched at least one call, or
+// exactly as many calls as it has been limited to.
+func (i *expectedInteraction) Verify() error {
+	matchedCalls := i.MatchedCalls()
+	expectedMatches := limitOf(i.interaction)
+	if len(matchedCalls) == 0 || len(matchedCalls) < expectedMatches {
+		return &UnmetExpectationError{
+			MethodName:      i.MethodName(),
+			Interaction:     i.interaction,
+			Matches:         len(matchedCalls),
+			MatchedCalls:    matchedCalls,
+			ExpectedMatches: expectedMatches,
+		}
+	}
+
 	return nil
 }
 
+func (i *expectedInteraction) CheckType(t reflect.Type) error {
+	return i.interaction.CheckType(t)
+}
+
+type negativeExpectedInteraction struct {
+	interaction Interaction
+	violation   error
+	mutex       sync.Mutex
+}
+
+func newNegativeExpectedInteraction(interaction Interaction) *negativeExpectedInteraction {
+	return &negativeExpectedInteraction{interaction: interaction}
+}
+
+func (i *negativeExpectedInteraction) MethodName() string {
+	return i.interaction.MethodName()
+}
+
+func (i *negativeExpectedInteraction) unwrap() Interaction {
+	return i.interaction
+}
+
+func (i *negativeExpectedInteraction) Call(methodName string, args []interface{}) ([]interface{}, bool, error) {
+	_, matches, _ := i.interaction.Call(methodName, args)
+	if !matches {
+		return nil, false, nil
+	}
+
+	violation := &UnexpectedCallError{MethodName: methodName, Args: args, NegativeExpectation: i.interaction}
+
+	i.mutex.Lock()
+	if i.violation == nil {
+		i.violation = violation
+	}
+	i.mutex.Unlock()
+
+	return nil, true, violation
+}
+
+func (i *negativeExpectedInteraction) Verify() error {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	return i.violation
+}
+
+// CheckType checks the method and the arguments of the wrapped interaction,
+// but not its return values, as negative expectations don't need any.
+func (i *negativeExpectedInteraction) CheckType(t reflect.Type) error {
+	method, err := lookupMethod(t, i.MethodName())
+	if err != nil {
+		return err
+	}
+
+	scopedInteraction, isScoped := unwrapInteraction(i.interaction).(methodScopedInteraction)
+	if !isScoped {
+		return nil
+	}
+
+	args, _ := scopedInteraction.literalArgs()
+	return checkArgs(t, method, args)
+}
+
 func assignable(leftType, rightType reflect.Type) bool {
 	if leftType == nil {
 		return isNillable(rightType)
@@ -261,6 +784,16 @@ func isNillable(t reflect.Type) bool {
 		t.Kind() == reflect.Map
 }
 
+// typeName returns the name of a type, falling back to its description for
+// unnamed types, e.g. function types.
+func typeName(t reflect.Type) string {
+	if t.Name() == "" {
+		return typeString(t)
+	}
+
+	return t.Name()
+}
+
 func typeString(t reflect.Type) string {
 	if t == nil {
 		return "nil"
@@ -269,14 +802,9 @@ func typeString(t reflect.Type) string {
 	return t.String()
 }
 
-func methodArgTypes(t reflect.Type, method reflect.Method) []reflect.Type {
+func methodArgTypes(method reflect.Method) []reflect.Type {
 	argTypes := []reflect.Type{}
-	fromIndex := 0
-	if t.Kind() != reflect.Interface {
-		fromIndex = 1
-	}
-
-	for i := fromIndex; i < method.Type.NumIn(); i++ {
+	for i := 0; i < method.Type.NumIn(); i++ {
 		argTypes = append(argTypes, method.Type.In(i))
 	}
 
@@ -0,0 +1,162 @@
+package moka
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// methodScopedInteraction is implemented by interactions that only match
+// calls to their own method, so that they can be indexed by method name. When
+// literalArgs returns true, the interaction also only matches calls whose
+// arguments are deeply equal to the returned ones.
+type methodScopedInteraction interface {
+	literalArgs() ([]interface{}, bool)
+}
+
+// wrappingInteraction is implemented by interactions that only match the calls
+// matched by the interaction they wrap.
+type wrappingInteraction interface {
+	unwrap() Interaction
+}
+
+// unwrapInteraction returns the innermost interaction wrapped by an
+// interaction.
+func unwrapInteraction(interaction Interaction) Interaction {
+	for {
+		wrapper, isWrapper := interaction.(wrappingInteraction)
+		if !isWrapper {
+			return interaction
+		}
+		interaction = wrapper.unwrap()
+	}
+}
+
+// interactionIndex finds the interactions that could match a call without
+// scanning all configured interactions. Interactions are kept in three
+// buckets, each in configuration order:
+//
+//   - unscoped interactions could match any call;
+//   - interactions scoped by method could match any call to their method;
+//   - interactions scoped by arguments could only match calls to their method
+//     with the same literal arguments.
+type interactionIndex struct {
+	unscoped []*configuredInteraction
+	byMethod map[string][]*configuredInteraction
+	byArgs   map[string][]*configuredInteraction
+}
+
+func newInteractionIndex(configuredInteractions []*configuredInteraction) *interactionIndex {
+	index := &interactionIndex{
+		unscoped: []*configuredInteraction{},
+		byMethod: map[string][]*configuredInteraction{},
+		byArgs:   map[string][]*configuredInteraction{},
+	}
+
+	for _, configuredInteraction := range configuredInteractions {
+		index.add(configuredInteraction)
+	}
+
+	return index
+}
+
+func (x *interactionIndex) add(configuredInteraction *configuredInteraction) {
+	interaction := unwrapInteraction(configuredInteraction.interaction)
+
+	scopedInteraction, isScoped := interaction.(methodScopedInteraction)
+	if !isScoped {
+		x.unscoped = append(x.unscoped, configuredInteraction)
+		return
+	}
+
+	methodName := interaction.MethodName()
+
+	args, hasLiteralArgs := scopedInteraction.literalArgs()
+	if hasLiteralArgs {
+		key, hashable := argsKey(methodName, args)
+		if hashable {
+			x.byArgs[key] = append(x.byArgs[key], configuredInteraction)
+			return
+		}
+	}
+
+	x.byMethod[methodName] = append(x.byMethod[methodName], configuredInteraction)
+}
+
+// candidates returns the interactions that could match a call, from the most
+// recently configured, which is the same order a linear scan would use.
+func (x *interactionIndex) candidates(methodName string, args []interface{}) candidateCursor {
+	var byArgs []*configuredInteraction
+	key, hashable := argsKey(methodName, args)
+	if hashable {
+		byArgs = x.byArgs[key]
+	}
+
+	return candidateCursor{lists: [3][]*configuredInteraction{x.unscoped, x.byMethod[methodName], byArgs}}
+}
+
+// candidateCursor merges lists of interactions sorted in configuration order
+// while iterating over them, from the most recently configured, so that no
+// merged list has to be allocated. As interactions are only ever appended to
+// the lists, a cursor keeps working after the lock is released.
+type candidateCursor struct {
+	lists [3][]*configuredInteraction
+}
+
+// next returns the most recently configured of the remaining interactions.
+func (c *candidateCursor) next() (*configuredInteraction, bool) {
+	mostRecent := -1
+	for i, list := range c.lists {
+		if len(list) == 0 {
+			continue
+		}
+
+		if mostRecent < 0 || list[len(list)-1].sequence > c.lists[mostRecent][len(c.lists[mostRecent])-1].sequence {
+			mostRecent = i
+		}
+	}
+
+	if mostRecent < 0 {
+		return nil, false
+	}
+
+	list := c.lists[mostRecent]
+	c.lists[mostRecent] = list[:len(list)-1]
+
+	return list[len(list)-1], true
+}
+
+// argsKey returns a key identifying a method call, if all of its arguments
+// are of a type for which equal keys are equivalent to deep equality: nil,
+// booleans, integers and strings. Floats are excluded, as 0 and -0 are equal
+// while NaN isn't equal to itself.
+func argsKey(methodName string, args []interface{}) (string, bool) {
+	var key strings.Builder
+	key.WriteString(methodName)
+
+	for _, arg := range args {
+		if !hashable(arg) {
+			return "", false
+		}
+
+		fmt.Fprintf(&key, "\x00%T:%#v", arg, arg)
+	}
+
+	return key.String(), true
+}
+
+func hashable(arg interface{}) bool {
+	if arg == nil {
+		return true
+	}
+
+	switch reflect.TypeOf(arg).Kind() {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.String:
+		return true
+	}
+
+	return false
+}
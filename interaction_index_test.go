@@ -0,0 +1,92 @@
+package moka
+
+import (
+	"math"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("interactionIndex", func() {
+	var index *interactionIndex
+	var configuredInteractions []*configuredInteraction
+
+	configure := func(interactions ...Interaction) {
+		for _, interaction := range interactions {
+			configuredInteraction := &configuredInteraction{interaction: interaction, sequence: len(configuredInteractions)}
+			configuredInteractions = append(configuredInteractions, configuredInteraction)
+			index.add(configuredInteraction)
+		}
+	}
+
+	candidateInteractions := func(methodName string, args ...interface{}) []Interaction {
+		interactions := []Interaction{}
+		cursor := index.candidates(methodName, args)
+		for candidate, found := cursor.next(); found; candidate, found = cursor.next() {
+			interactions = append(interactions, candidate.interaction)
+		}
+		return interactions
+	}
+
+	BeforeEach(func() {
+		index = newInteractionIndex(nil)
+		configuredInteractions = nil
+	})
+
+	It("returns the interactions that could match a call, from the most recently configured", func() {
+		literalInteraction := newArgsInteraction("UltimateQuestion", []interface{}{"life"}, nil)
+		otherLiteralInteraction := newArgsInteraction("UltimateQuestion", []interface{}{"universe"}, nil)
+		anyArgsInteraction := newArgsInteraction("UltimateQuestion", nil, nil)
+		otherMethodInteraction := newArgsInteraction("UltimateAnswer", nil, nil)
+		customInteraction := newFakeInteraction(nil, false, nil, nil)
+		expectedLiteralInteraction := newExpectedInteraction(newArgsInteraction("UltimateQuestion", []interface{}{"life"}, nil))
+
+		configure(
+			literalInteraction,
+			customInteraction,
+			otherLiteralInteraction,
+			anyArgsInteraction,
+			otherMethodInteraction,
+			expectedLiteralInteraction,
+		)
+
+		Expect(candidateInteractions("UltimateQuestion", "life")).To(Equal([]Interaction{
+			expectedLiteralInteraction,
+			anyArgsInteraction,
+			customInteraction,
+			literalInteraction,
+		}))
+	})
+
+	It("doesn't index arguments that aren't comparable by key", func() {
+		sliceInteraction := newArgsInteraction("UltimateQuestionWithSlice", []interface{}{[]string{"life"}}, nil)
+		floatInteraction := newArgsInteraction("UltimateQuestion", []interface{}{0.0}, nil)
+		matcherInteraction := newArgsInteraction("UltimateQuestion", []interface{}{NewCaptor[string]()}, nil)
+
+		configure(sliceInteraction, floatInteraction, matcherInteraction)
+
+		Expect(candidateInteractions("UltimateQuestionWithSlice", []string{"life"})).To(Equal([]Interaction{sliceInteraction}))
+		Expect(candidateInteractions("UltimateQuestion", math.Copysign(0, -1))).To(Equal([]Interaction{matcherInteraction, floatInteraction}))
+	})
+
+	It("distinguishes arguments of different types", func() {
+		intInteraction := newArgsInteraction("UltimateQuestion", []interface{}{42}, nil)
+		int64Interaction := newArgsInteraction("UltimateQuestion", []interface{}{int64(42)}, nil)
+
+		configure(intInteraction, int64Interaction)
+
+		Expect(candidateInteractions("UltimateQuestion", 42)).To(Equal([]Interaction{intInteraction}))
+		Expect(candidateInteractions("UltimateQuestion", int64(42))).To(Equal([]Interaction{int64Interaction}))
+		Expect(candidateInteractions("UltimateQuestion", "42")).To(BeEmpty())
+	})
+
+	It("can be rebuilt from a list of interactions", func() {
+		literalInteraction := newArgsInteraction("UltimateQuestion", []interface{}{"life"}, nil)
+		anyArgsInteraction := newArgsInteraction("UltimateQuestion", nil, nil)
+		configure(literalInteraction, anyArgsInteraction)
+
+		index = newInteractionIndex(configuredInteractions)
+
+		Expect(candidateInteractions("UltimateQuestion", "life")).To(Equal([]Interaction{anyArgsInteraction, literalInteraction}))
+	})
+})
@@ -11,7 +11,7 @@ import (
 
 var _ = Describe("interaction", func() {
 	Describe("argsInteraction", func() {
-		var interaction interaction
+		var interaction Interaction
 
 		Describe("call", func() {
 			var matched bool
@@ -27,7 +27,7 @@ var _ = Describe("interaction", func() {
 
 			Context("when both the method name and the args match", func() {
 				JustBeforeEach(func() {
-					returnValues, matched = interaction.call("UltimateQuestion", []interface{}{"life", "universe", "everything"})
+					returnValues, matched, _ = interaction.Call("UltimateQuestion", []interface{}{"life", "universe", "everything"})
 				})
 
 				It("matches and returns its return values", func() {
@@ -38,7 +38,7 @@ var _ = Describe("interaction", func() {
 
 			Context("when the method name doesn't match", func() {
 				JustBeforeEach(func() {
-					returnValues, matched = interaction.call("DomandaFondamentale", []interface{}{"life", "universe", "everything"})
+					returnValues, matched, _ = interaction.Call("DomandaFondamentale", []interface{}{"life", "universe", "everything"})
 				})
 
 				It("doesn't match and returns nil", func() {
@@ -49,7 +49,7 @@ var _ = Describe("interaction", func() {
 
 			Context("when the arguments don't match", func() {
 				JustBeforeEach(func() {
-					returnValues, matched = interaction.call("UltimateQuestion", []interface{}{"vita", "universo", "tutto quanto"})
+					returnValues, matched, _ = interaction.Call("UltimateQuestion", []interface{}{"vita", "universo", "tutto quanto"})
 				})
 
 				It("doesn't match and returns nil", func() {
@@ -60,7 +60,7 @@ var _ = Describe("interaction", func() {
 
 			Context("when both method name and the arguments don't match", func() {
 				JustBeforeEach(func() {
-					returnValues, matched = interaction.call("DomandaFondamentale", []interface{}{"vita", "universo", "tutto quanto"})
+					returnValues, matched, _ = interaction.Call("DomandaFondamentale", []interface{}{"vita", "universo", "tutto quanto"})
 				})
 
 				It("doesn't match and returns nil", func() {
@@ -70,13 +70,43 @@ var _ = Describe("interaction", func() {
 			})
 		})
 
+		Describe("call with a captor", func() {
+			var captor *Captor[string]
+
+			BeforeEach(func() {
+				captor = NewCaptor[string]()
+				interaction = newArgsInteraction(
+					"UltimateQuestion",
+					[]interface{}{"life", captor, "everything"},
+					[]interface{}{42, nil},
+				)
+			})
+
+			It("matches any argument of the captured type and captures it", func() {
+				_, matched, _ := interaction.Call("UltimateQuestion", []interface{}{"life", "universe", "everything"})
+				Expect(matched).To(BeTrue())
+
+				_, matched, _ = interaction.Call("UltimateQuestion", []interface{}{"life", "multiverse", "everything"})
+				Expect(matched).To(BeTrue())
+
+				Expect(captor.All()).To(Equal([]string{"universe", "multiverse"}))
+			})
+
+			It("doesn't capture anything when other arguments don't match", func() {
+				_, matched, _ := interaction.Call("UltimateQuestion", []interface{}{"vita", "universo", "tutto quanto"})
+
+				Expect(matched).To(BeFalse())
+				Expect(captor.All()).To(BeEmpty())
+			})
+		})
+
 		Describe("verify", func() {
 			BeforeEach(func() {
 				interaction = newArgsInteraction("", nil, nil)
 			})
 
 			It("does nothing and always returns nil", func() {
-				Expect(interaction.verify()).To(BeNil())
+				Expect(interaction.Verify()).To(BeNil())
 			})
 		})
 
@@ -85,7 +115,7 @@ var _ = Describe("interaction", func() {
 				var checkTypeError error
 
 				JustBeforeEach(func() {
-					checkTypeError = interaction.checkType(t)
+					checkTypeError = interaction.CheckType(t)
 				})
 
 				Context("when the method is defined and all types match", func() {
@@ -144,6 +174,34 @@ var _ = Describe("interaction", func() {
 					})
 				})
 
+				Context("when a captor is specified for an argument of a compatible type", func() {
+					BeforeEach(func() {
+						interaction = newArgsInteraction(
+							"UltimateQuestion",
+							[]interface{}{"life", "universe", NewCaptor[string]()},
+							[]interface{}{42, nil},
+						)
+					})
+
+					It("succeeds", func() {
+						Expect(checkTypeError).NotTo(HaveOccurred())
+					})
+				})
+
+				Context("when a captor is specified for an argument of an incompatible type", func() {
+					BeforeEach(func() {
+						interaction = newArgsInteraction(
+							"UltimateQuestion",
+							[]interface{}{"life", "universe", NewCaptor[int]()},
+							[]interface{}{42, nil},
+						)
+					})
+
+					It("fails", func() {
+						Expect(checkTypeError).To(MatchError(fmt.Sprintf("Invalid interaction: type of argument 3 of method '%s.UltimateQuestion' is 'string', matcher for 'int' given", t.Name())))
+					})
+				})
+
 				Context("when nil is specified for a non-nillable type argument", func() {
 					BeforeEach(func() {
 						interaction = newArgsInteraction(
@@ -235,7 +293,7 @@ var _ = Describe("interaction", func() {
 			Describe("call", func() {
 				Context("when the method name matches", func() {
 					JustBeforeEach(func() {
-						returnValues, matched = interaction.call("UltimateQuestion", []interface{}{"anything"})
+						returnValues, matched, _ = interaction.Call("UltimateQuestion", []interface{}{"anything"})
 					})
 
 					It("matches and returns its return values", func() {
@@ -246,7 +304,7 @@ var _ = Describe("interaction", func() {
 
 				Context("when the method name doesn't match", func() {
 					JustBeforeEach(func() {
-						returnValues, matched = interaction.call("DomandaFondamentale", []interface{}{"anything"})
+						returnValues, matched, _ = interaction.Call("DomandaFondamentale", []interface{}{"anything"})
 					})
 
 					It("doesn't match and returns nil", func() {
@@ -258,7 +316,7 @@ var _ = Describe("interaction", func() {
 
 			Describe("checkType", func() {
 				JustBeforeEach(func() {
-					checkTypeError = interaction.checkType(reflect.TypeOf(myDeepThought{}))
+					checkTypeError = interaction.CheckType(reflect.TypeOf(myDeepThought{}))
 				})
 
 				Context("when the method is defined", func() {
@@ -278,11 +336,11 @@ var _ = Describe("interaction", func() {
 		var matched bool
 
 		var fakeInteraction *fakeInteraction
-		var expectedInteraction interaction
+		var expectedInteraction Interaction
 
 		JustBeforeEach(func() {
 			expectedInteraction = newExpectedInteraction(fakeInteraction)
-			returnValues, matched = expectedInteraction.call(expectedMethodName, expectedArgs)
+			returnValues, matched, _ = expectedInteraction.Call(expectedMethodName, expectedArgs)
 		})
 
 		Context("when called with the expected method name and args", func() {
@@ -293,7 +351,7 @@ var _ = Describe("interaction", func() {
 			It("delegates to the wrapped interaction and records the call for verification", func() {
 				Expect(returnValues).To(Equal([]interface{}{42, nil}))
 				Expect(matched).To(Equal(true))
-				Expect(expectedInteraction.verify()).To(BeNil())
+				Expect(expectedInteraction.Verify()).To(BeNil())
 			})
 		})
 
@@ -305,13 +363,130 @@ var _ = Describe("interaction", func() {
 			It("delegates to the wrapped interaction but doesn't record the call for verification", func() {
 				Expect(returnValues).To(BeNil())
 				Expect(matched).To(Equal(false))
-				Expect(expectedInteraction.verify()).To(MatchError("Expected interaction: <the-interaction-string-representation>"))
+				Expect(expectedInteraction.Verify()).To(MatchError("Expected interaction: <the-interaction-string-representation>"))
+			})
+		})
+
+		Context("when type checked", func() {
+			BeforeEach(func() {
+				fakeInteraction = newFakeInteraction(nil, false, nil, errors.New("invalid"))
+			})
+
+			It("delegates to the wrapped interaction", func() {
+				Expect(expectedInteraction.CheckType(reflect.TypeOf(myDeepThought{}))).To(MatchError("invalid"))
+			})
+		})
+
+		Context("when called with unexpected method names or args after a matching call", func() {
+			BeforeEach(func() {
+				fakeInteraction = newFakeInteraction([]interface{}{42, nil}, true, nil, nil)
+			})
+
+			JustBeforeEach(func() {
+				fakeInteraction.matches = false
+				returnValues, matched, _ = expectedInteraction.Call("DomandaFondamentale", []interface{}{"vita"})
+			})
+
+			It("still considers the expectation satisfied", func() {
+				Expect(matched).To(BeFalse())
+				Expect(expectedInteraction.Verify()).To(BeNil())
+			})
+		})
+
+		Context("when called multiple times", func() {
+			BeforeEach(func() {
+				fakeInteraction = newFakeInteraction([]interface{}{42, nil}, true, nil, nil)
+			})
+
+			JustBeforeEach(func() {
+				expectedInteraction.Call(expectedMethodName, []interface{}{"vita", "universo", "tutto quanto"})
+				fakeInteraction.matches = false
+				expectedInteraction.Call("DomandaFondamentale", expectedArgs)
+			})
+
+			It("keeps the history of the matched calls", func() {
+				Expect(expectedInteraction.(Expectation).Matches()).To(Equal(2))
+				Expect(expectedInteraction.(Expectation).MatchedCalls()).To(Equal([]Call{
+					{MethodName: expectedMethodName, Args: expectedArgs, InteractionIndex: 0},
+					{MethodName: expectedMethodName, Args: []interface{}{"vita", "universo", "tutto quanto"}, InteractionIndex: 1},
+				}))
+			})
+		})
+
+		Context("when the wrapped interaction is limited to a number of calls", func() {
+			BeforeEach(func() {
+				fakeInteraction = newFakeInteraction([]interface{}{42, nil}, true, nil, nil)
+			})
+
+			It("is only satisfied once it has matched all of them", func() {
+				limitedExpectedInteraction := newExpectedInteraction(newLimitedInteraction(fakeInteraction, 2))
+
+				limitedExpectedInteraction.Call(expectedMethodName, expectedArgs)
+				err := limitedExpectedInteraction.Verify()
+				Expect(err).To(MatchError("Expected interaction: <the-interaction-string-representation> 2 times (matched 1)"))
+				Expect(err.(*UnmetExpectationError).MatchedCalls).To(Equal([]Call{
+					{MethodName: expectedMethodName, Args: expectedArgs, InteractionIndex: 0},
+				}))
+
+				limitedExpectedInteraction.Call(expectedMethodName, expectedArgs)
+				Expect(limitedExpectedInteraction.Verify()).To(BeNil())
+			})
+		})
+	})
+
+	Describe("negativeExpectedInteraction", func() {
+		var fakeInteraction *fakeInteraction
+		var negativeExpectedInteraction Interaction
+
+		JustBeforeEach(func() {
+			negativeExpectedInteraction = newNegativeExpectedInteraction(fakeInteraction)
+		})
+
+		Context("when called with matching method name and args", func() {
+			BeforeEach(func() {
+				fakeInteraction = newFakeInteraction([]interface{}{42, nil}, true, nil, nil)
+			})
+
+			It("matches, returns an error and records the violation for verification", func() {
+				returnValues, matched, err := negativeExpectedInteraction.Call("UltimateQuestion", []interface{}{"life"})
+
+				Expect(returnValues).To(BeNil())
+				Expect(matched).To(BeTrue())
+				Expect(err).To(MatchError("Unexpected interaction: UltimateQuestion(\"life\") (expected not to receive <the-interaction-string-representation>)"))
+				Expect(negativeExpectedInteraction.Verify()).To(MatchError(err))
+			})
+		})
+
+		Context("when called with non-matching method names or args", func() {
+			BeforeEach(func() {
+				fakeInteraction = newFakeInteraction(nil, false, nil, nil)
+			})
+
+			It("doesn't match and doesn't record any violation", func() {
+				returnValues, matched, err := negativeExpectedInteraction.Call("UltimateQuestion", []interface{}{"life"})
+
+				Expect(returnValues).To(BeNil())
+				Expect(matched).To(BeFalse())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(negativeExpectedInteraction.Verify()).To(Succeed())
+			})
+		})
+
+		Describe("CheckType", func() {
+			It("checks the method and the arguments, but not the return values", func() {
+				Expect(newNegativeExpectedInteraction(newArgsInteraction("UltimateQuestion", []interface{}{"life", "universe", "everything"}, nil)).CheckType(reflect.TypeOf(myDeepThought{}))).To(Succeed())
+				Expect(newNegativeExpectedInteraction(newArgsInteraction("UltimateGuess", nil, nil)).CheckType(reflect.TypeOf(myDeepThought{}))).To(
+					MatchError("Invalid interaction: type 'myDeepThought' has no method 'UltimateGuess'"),
+				)
+				Expect(newNegativeExpectedInteraction(newArgsInteraction("UltimateQuestion", []interface{}{"life"}, nil)).CheckType(reflect.TypeOf(myDeepThought{}))).To(
+					MatchError("Invalid interaction: method 'myDeepThought.UltimateQuestion' takes 3 arguments, 1 specified"),
+				)
 			})
 		})
 	})
 
 	Describe("bodyInteraction", func() {
-		var interaction interaction
+		var interaction Interaction
 
 		BeforeEach(func() {
 			interaction = newBodyInteraction(
@@ -329,33 +504,110 @@ var _ = Describe("interaction", func() {
 		Describe("call", func() {
 			var matched bool
 			var returnValues []interface{}
+			var callError error
 
 			Context("when the method name matches", func() {
 				JustBeforeEach(func() {
-					returnValues, matched = interaction.call("UltimateQuestion", []interface{}{"life", "universe", "everything"})
+					returnValues, matched, callError = interaction.Call("UltimateQuestion", []interface{}{"life", "universe", "everything"})
 				})
 
 				It("matches and returns the return values from the body", func() {
 					Expect(returnValues).To(Equal([]interface{}{42, nil}))
 					Expect(matched).To(BeTrue())
+					Expect(callError).NotTo(HaveOccurred())
 				})
 			})
 
 			Context("when the method name doesn't match", func() {
 				JustBeforeEach(func() {
-					returnValues, matched = interaction.call("DomandaFondamentale", []interface{}{"life", "universe", "everything"})
+					returnValues, matched, callError = interaction.Call("DomandaFondamentale", []interface{}{"life", "universe", "everything"})
 				})
 
 				It("matches and returns the return values from the body", func() {
 					Expect(returnValues).To(BeNil())
 					Expect(matched).To(BeFalse())
+					Expect(callError).NotTo(HaveOccurred())
+				})
+			})
+
+			Context("when some arguments are nil", func() {
+				BeforeEach(func() {
+					interaction = newBodyInteraction(
+						"UltimateQuestionWithSlice",
+						func(things []string) (int, error) {
+							if things == nil {
+								return 42, nil
+							}
+
+							return 0, errors.New("NOPE")
+						},
+					)
+				})
+
+				JustBeforeEach(func() {
+					returnValues, matched, callError = interaction.Call("UltimateQuestionWithSlice", []interface{}{nil})
+				})
+
+				It("passes zero values of the right type to the body", func() {
+					Expect(returnValues).To(Equal([]interface{}{42, nil}))
+					Expect(matched).To(BeTrue())
+					Expect(callError).NotTo(HaveOccurred())
+				})
+			})
+
+			Context("when the body is variadic", func() {
+				BeforeEach(func() {
+					interaction = newBodyInteraction(
+						"UltimateQuestionWithErrors",
+						func(topic string, errs ...error) int {
+							return len(errs)
+						},
+					)
+				})
+
+				It("passes nil variadic arguments as zero values of the element type", func() {
+					returnValues, matched, callError = interaction.Call("UltimateQuestionWithErrors", []interface{}{"life", nil, nil})
+
+					Expect(returnValues).To(Equal([]interface{}{2}))
+					Expect(matched).To(BeTrue())
+					Expect(callError).NotTo(HaveOccurred())
+				})
+
+				It("passes variadic arguments received as a slice", func() {
+					returnValues, matched, callError = interaction.Call("UltimateQuestionWithErrors", []interface{}{"life", []error{nil, nil, nil}})
+
+					Expect(returnValues).To(Equal([]interface{}{3}))
+					Expect(matched).To(BeTrue())
+					Expect(callError).NotTo(HaveOccurred())
+				})
+			})
+
+			Context("when the body panics", func() {
+				BeforeEach(func() {
+					interaction = newBodyInteraction(
+						"UltimateQuestion",
+						func(topicOne, topicTwo, topicThree string) (int, error) {
+							panic("don't panic")
+						},
+					)
+				})
+
+				JustBeforeEach(func() {
+					returnValues, matched, callError = interaction.Call("UltimateQuestion", []interface{}{"life", "universe", "everything"})
+				})
+
+				It("matches and returns an error describing the panic", func() {
+					Expect(returnValues).To(BeNil())
+					Expect(matched).To(BeTrue())
+					Expect(callError).To(MatchError(HavePrefix("Panic in interaction: UltimateQuestion(\"life\", \"universe\", \"everything\") (matched by UltimateQuestion with body func(string, string, string) (int, error))\ndon't panic\n\n")))
+					Expect(callError.Error()).To(ContainSubstring("runtime/debug.Stack"))
 				})
 			})
 		})
 
 		Describe("verify", func() {
 			It("does nothing and returns nil", func() {
-				Expect(interaction.verify()).To(BeNil())
+				Expect(interaction.Verify()).To(BeNil())
 			})
 		})
 
@@ -364,7 +616,7 @@ var _ = Describe("interaction", func() {
 				var checkTypeError error
 
 				JustBeforeEach(func() {
-					checkTypeError = interaction.checkType(t)
+					checkTypeError = interaction.CheckType(t)
 				})
 
 				Context("when the method is defined and all types match", func() {
@@ -450,15 +702,143 @@ var _ = Describe("interaction", func() {
 			TestCheckType(reflect.TypeOf(myDeepThought{}))
 		})
 	})
+
+	Describe("panicInteraction", func() {
+		var interaction Interaction
+
+		BeforeEach(func() {
+			interaction = newPanicInteraction(
+				"UltimateQuestion",
+				[]interface{}{"life", "universe", "everything"},
+				"don't panic",
+			)
+		})
+
+		Describe("call", func() {
+			var matched bool
+			var returnValues []interface{}
+			var callError error
+
+			Context("when both the method name and the args match", func() {
+				JustBeforeEach(func() {
+					returnValues, matched, callError = interaction.Call("UltimateQuestion", []interface{}{"life", "universe", "everything"})
+				})
+
+				It("matches and asks the double to panic", func() {
+					Expect(returnValues).To(BeNil())
+					Expect(matched).To(BeTrue())
+					Expect(callError).To(Equal(interactionPanic{value: "don't panic"}))
+				})
+			})
+
+			Context("when the arguments don't match", func() {
+				JustBeforeEach(func() {
+					returnValues, matched, callError = interaction.Call("UltimateQuestion", []interface{}{"vita", "universo", "tutto quanto"})
+				})
+
+				It("doesn't match", func() {
+					Expect(returnValues).To(BeNil())
+					Expect(matched).To(BeFalse())
+					Expect(callError).NotTo(HaveOccurred())
+				})
+			})
+		})
+
+		Describe("checkType", func() {
+			It("checks the method name and the arguments, but not the return values", func() {
+				Expect(interaction.CheckType(reflect.TypeOf(myDeepThought{}))).To(Succeed())
+				Expect(newPanicInteraction("UltimateQuestion", []interface{}{"life"}, nil).CheckType(reflect.TypeOf(myDeepThought{}))).To(
+					MatchError("Invalid interaction: method 'myDeepThought.UltimateQuestion' takes 3 arguments, 1 specified"),
+				)
+				Expect(newPanicInteraction("WorstQuestion", nil, nil).CheckType(reflect.TypeOf(myDeepThought{}))).To(
+					MatchError("Invalid interaction: type 'myDeepThought' has no method 'WorstQuestion'"),
+				)
+			})
+		})
+	})
+
+	Describe("returnErrorInteraction", func() {
+		var interaction Interaction
+		var err = errors.New("NOPE")
+
+		BeforeEach(func() {
+			interaction = newReturnErrorInteraction("UltimateQuestion", nil, err)
+		})
+
+		Describe("call", func() {
+			Context("when the type hasn't been checked", func() {
+				It("returns the error only", func() {
+					returnValues, matched, callError := interaction.Call("UltimateQuestion", []interface{}{"life", "universe", "everything"})
+
+					Expect(returnValues).To(Equal([]interface{}{err}))
+					Expect(matched).To(BeTrue())
+					Expect(callError).NotTo(HaveOccurred())
+				})
+			})
+
+			Context("when the type has been checked", func() {
+				BeforeEach(func() {
+					Expect(interaction.CheckType(reflect.TypeOf(myDeepThought{}))).To(Succeed())
+				})
+
+				It("returns the error and zero values for the other return values", func() {
+					returnValues, matched, callError := interaction.Call("UltimateQuestion", []interface{}{"life", "universe", "everything"})
+
+					Expect(returnValues).To(Equal([]interface{}{0, err}))
+					Expect(matched).To(BeTrue())
+					Expect(callError).NotTo(HaveOccurred())
+				})
+			})
+
+			Context("when the method name doesn't match", func() {
+				It("doesn't match", func() {
+					returnValues, matched, _ := interaction.Call("DomandaFondamentale", []interface{}{"life", "universe", "everything"})
+
+					Expect(returnValues).To(BeNil())
+					Expect(matched).To(BeFalse())
+				})
+			})
+		})
+
+		Describe("checkType", func() {
+			Context("when the method doesn't return an error", func() {
+				BeforeEach(func() {
+					interaction = newReturnErrorInteraction("UltimateAnswer", nil, err)
+				})
+
+				It("fails", func() {
+					Expect(interaction.CheckType(reflect.TypeOf(myDeepThought{}))).To(
+						MatchError("Invalid interaction: method 'myDeepThought.UltimateAnswer' doesn't return an error"),
+					)
+				})
+			})
+		})
+	})
 })
 
 type deepThought interface {
 	UltimateQuestion(topicOne, topicTwo, topicThree string) (int, error)
 	UltimateQuestionWithSlice(things []string) (int, error)
+	UltimateAnswer() int
+	UltimateAnswerInto(answer *int) error
+	RepeatQuestion(question string) (string, error)
 }
 
 type myDeepThought struct{}
 
+func (dt myDeepThought) UltimateAnswer() int {
+	return 42
+}
+
+func (dt myDeepThought) RepeatQuestion(question string) (string, error) {
+	return question, nil
+}
+
+func (dt myDeepThought) UltimateAnswerInto(answer *int) error {
+	*answer = 42
+	return nil
+}
+
 func (dt myDeepThought) UltimateQuestion(topicOne, topicTwo, topicThree string) (int, error) {
 	return 42, nil
 }
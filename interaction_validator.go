@@ -2,28 +2,63 @@ package moka
 
 import "reflect"
 
-type interactionValidator interface {
-	validate(interaction interaction) error
+// InteractionValidator validates interactions before they are configured on a
+// `StrictDouble`.
+type InteractionValidator interface {
+	Validate(interaction Interaction) error
 }
 
-type typeInteractionValidator struct {
+// selfValidatingInteraction is implemented by interactions that can be
+// invalid regardless of the type of the double, e.g. because they are
+// misconfigured.
+type selfValidatingInteraction interface {
+	validate() error
+}
+
+// validateInteraction validates an interaction, and all the interactions it
+// wraps, on their own.
+func validateInteraction(interaction Interaction) error {
+	for {
+		if selfValidating, isSelfValidating := interaction.(selfValidatingInteraction); isSelfValidating {
+			err := selfValidating.validate()
+			if err != nil {
+				return err
+			}
+		}
+
+		wrapper, isWrapper := interaction.(wrappingInteraction)
+		if !isWrapper {
+			return nil
+		}
+		interaction = wrapper.unwrap()
+	}
+}
+
+// TypeInteractionValidator checks that interactions match a type.
+type TypeInteractionValidator struct {
 	t reflect.Type
 }
 
-func newTypeInteractionValidator(t reflect.Type) typeInteractionValidator {
-	return typeInteractionValidator{t: t}
+// NewTypeInteractionValidator instantiates a new `TypeInteractionValidator`
+// for the specified type.
+func NewTypeInteractionValidator(t reflect.Type) TypeInteractionValidator {
+	return TypeInteractionValidator{t: t}
 }
 
-func (v typeInteractionValidator) validate(interaction interaction) error {
-	return interaction.checkType(v.t)
+// Validate returns an error if the interaction doesn't match the type.
+func (v TypeInteractionValidator) Validate(interaction Interaction) error {
+	return interaction.CheckType(v.t)
 }
 
-type nullInteractionValidator struct{}
+// NullInteractionValidator accepts any interaction.
+type NullInteractionValidator struct{}
 
-func newNullInteractionValidator() nullInteractionValidator {
-	return nullInteractionValidator{}
+// NewNullInteractionValidator instantiates a new `NullInteractionValidator`.
+func NewNullInteractionValidator() NullInteractionValidator {
+	return NullInteractionValidator{}
 }
 
-func (v nullInteractionValidator) validate(interaction interaction) error {
+// Validate always returns nil.
+func (v NullInteractionValidator) Validate(interaction Interaction) error {
 	return nil
 }
@@ -10,29 +10,29 @@ import (
 
 var _ = Describe("InteractionValidator", func() {
 	Describe("NullInteractionValidator", func() {
-		var nullInteractionValidator nullInteractionValidator
+		var nullInteractionValidator NullInteractionValidator
 
 		BeforeEach(func() {
-			nullInteractionValidator = newNullInteractionValidator()
+			nullInteractionValidator = NewNullInteractionValidator()
 		})
 
 		It("never returns an error", func() {
-			Expect(nullInteractionValidator.validate(nil)).To(BeNil())
-			Expect(nullInteractionValidator.validate(newFakeInteraction(nil, false, nil, nil))).To(BeNil())
+			Expect(nullInteractionValidator.Validate(nil)).To(BeNil())
+			Expect(nullInteractionValidator.Validate(newFakeInteraction(nil, false, nil, nil))).To(BeNil())
 		})
 	})
 
 	Describe("TypeInteractionValidator", func() {
 		var fakeInteraction *fakeInteraction
-		var typeInteractionValidator typeInteractionValidator
+		var typeInteractionValidator TypeInteractionValidator
 
 		BeforeEach(func() {
 			fakeInteraction = newFakeInteraction(nil, false, nil, errors.New("CheckType failed"))
-			typeInteractionValidator = newTypeInteractionValidator(reflect.TypeOf(someType{}))
+			typeInteractionValidator = NewTypeInteractionValidator(reflect.TypeOf(someType{}))
 		})
 
 		It("checks the interaction against the type", func() {
-			err := typeInteractionValidator.validate(fakeInteraction)
+			err := typeInteractionValidator.Validate(fakeInteraction)
 
 			Expect(err).To(MatchError("CheckType failed"))
 			Expect(fakeInteraction.checkTypeCalled).To(BeTrue())
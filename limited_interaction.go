@@ -0,0 +1,92 @@
+package moka
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// limitedInteraction wraps an interaction, only matching the first calls
+// matched by it. Once expired, calls fall through to other interactions.
+type limitedInteraction struct {
+	interaction Interaction
+	times       int
+	remaining   int
+	mutex       sync.Mutex
+}
+
+func newLimitedInteraction(interaction Interaction, times int) *limitedInteraction {
+	return &limitedInteraction{interaction: interaction, times: times, remaining: times}
+}
+
+// limitOf returns the number of calls an interaction, or one of the
+// interactions it wraps, is limited to, or 0 if it isn't limited.
+func limitOf(interaction Interaction) int {
+	for {
+		if limited, isLimited := interaction.(*limitedInteraction); isLimited {
+			return limited.times
+		}
+
+		wrapper, isWrapper := interaction.(wrappingInteraction)
+		if !isWrapper {
+			return 0
+		}
+		interaction = wrapper.unwrap()
+	}
+}
+
+func (i *limitedInteraction) MethodName() string {
+	return i.interaction.MethodName()
+}
+
+func (i *limitedInteraction) unwrap() Interaction {
+	return i.interaction
+}
+
+// Call reserves one of the remaining matches before calling the wrapped
+// interaction, which might block, and gives it back if the call doesn't
+// match.
+func (i *limitedInteraction) Call(methodName string, args []interface{}) ([]interface{}, bool, error) {
+	i.mutex.Lock()
+	if i.remaining == 0 {
+		i.mutex.Unlock()
+		return nil, false, nil
+	}
+	i.remaining--
+	i.mutex.Unlock()
+
+	returnValues, matches, err := i.interaction.Call(methodName, args)
+	if !matches {
+		i.mutex.Lock()
+		i.remaining++
+		i.mutex.Unlock()
+	}
+
+	return returnValues, matches, err
+}
+
+func (i *limitedInteraction) Verify() error {
+	return i.interaction.Verify()
+}
+
+func (i *limitedInteraction) String() string {
+	if i.times == 1 {
+		return fmt.Sprintf("%s once", i.interaction)
+	}
+
+	return fmt.Sprintf("%s %d times", i.interaction, i.times)
+}
+
+// validate returns an error if the interaction isn't limited to a positive
+// number of calls.
+func (i *limitedInteraction) validate() error {
+	if i.times <= 0 {
+		return newInvalidInteractionError(i.MethodName(), "the number of calls to '%s' must be positive, %d given", i.MethodName(), i.times)
+	}
+
+	return nil
+}
+
+func (i *limitedInteraction) CheckType(t reflect.Type) error {
+	return i.interaction.CheckType(t)
+}
@@ -0,0 +1,62 @@
+package moka
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("limitedInteraction", func() {
+	var fakeInteraction *fakeInteraction
+	var interaction Interaction
+
+	BeforeEach(func() {
+		fakeInteraction = newFakeInteraction([]interface{}{42}, true, nil, nil)
+		interaction = newLimitedInteraction(fakeInteraction, 2)
+	})
+
+	It("only matches the first matching calls", func() {
+		for i := 0; i < 2; i++ {
+			returnValues, matched, _ := interaction.Call("UltimateQuestion", []interface{}{"life"})
+			Expect(matched).To(BeTrue())
+			Expect(returnValues).To(Equal([]interface{}{42}))
+		}
+
+		fakeInteraction.callCalled = false
+		_, matched, _ := interaction.Call("UltimateQuestion", []interface{}{"life"})
+		Expect(matched).To(BeFalse())
+		Expect(fakeInteraction.callCalled).To(BeFalse())
+	})
+
+	It("doesn't count calls that don't match", func() {
+		fakeInteraction.matches = false
+		interaction.Call("UltimateQuestion", []interface{}{"life"})
+		interaction.Call("UltimateQuestion", []interface{}{"life"})
+
+		fakeInteraction.matches = true
+		_, matched, _ := interaction.Call("UltimateQuestion", []interface{}{"life"})
+		Expect(matched).To(BeTrue())
+	})
+
+	It("is described with the number of calls", func() {
+		Expect(fmt.Sprint(interaction)).To(Equal("<the-interaction-string-representation> 2 times"))
+		Expect(fmt.Sprint(newLimitedInteraction(fakeInteraction, 1))).To(Equal("<the-interaction-string-representation> once"))
+	})
+
+	It("is invalid unless limited to a positive number of calls", func() {
+		Expect(newLimitedInteraction(fakeInteraction, 1).validate()).To(Succeed())
+		fakeInteraction.methodName = "UltimateQuestion"
+		Expect(newLimitedInteraction(fakeInteraction, 0).validate()).To(MatchError("Invalid interaction: the number of calls to 'UltimateQuestion' must be positive, 0 given"))
+	})
+
+	It("delegates verification and type checking to the wrapped interaction", func() {
+		fakeInteraction = newFakeInteraction(nil, false, errors.New("unmet"), errors.New("invalid"))
+		interaction = newLimitedInteraction(fakeInteraction, 1)
+
+		Expect(interaction.Verify()).To(MatchError("unmet"))
+		Expect(interaction.CheckType(reflect.TypeOf(myDeepThought{}))).To(MatchError("invalid"))
+	})
+})
@@ -15,11 +15,13 @@ func TestMoka(t *testing.T) {
 }
 
 type fakeInteraction struct {
+	methodName         string
 	callCalled         bool
 	receivedMethodName string
 	receivedArgs       []interface{}
 	returnValues       []interface{}
 	matches            bool
+	callError          error
 	verifyCalled       bool
 	verifyError        error
 	checkTypeCalled    bool
@@ -31,19 +33,23 @@ func newFakeInteraction(returnValues []interface{}, matches bool, verifyError er
 	return &fakeInteraction{returnValues: returnValues, matches: matches, verifyError: verifyError, checkTypeError: checkTypeError}
 }
 
-func (i *fakeInteraction) call(methodName string, args []interface{}) ([]interface{}, bool) {
+func (i *fakeInteraction) MethodName() string {
+	return i.methodName
+}
+
+func (i *fakeInteraction) Call(methodName string, args []interface{}) ([]interface{}, bool, error) {
 	i.callCalled = true
 	i.receivedMethodName = methodName
 	i.receivedArgs = args
-	return i.returnValues, i.matches
+	return i.returnValues, i.matches, i.callError
 }
 
-func (i *fakeInteraction) verify() error {
+func (i *fakeInteraction) Verify() error {
 	i.verifyCalled = true
 	return i.verifyError
 }
 
-func (i *fakeInteraction) checkType(t reflect.Type) error {
+func (i *fakeInteraction) CheckType(t reflect.Type) error {
 	i.checkTypeCalled = true
 	i.receivedType = t
 	return i.checkTypeError
@@ -61,6 +67,6 @@ func newFakeInteractionValidator(validationError error) fakeInteractionValidator
 	return fakeInteractionValidator{validationError: validationError}
 }
 
-func (v fakeInteractionValidator) validate(interaction interaction) error {
+func (v fakeInteractionValidator) Validate(interaction Interaction) error {
 	return v.validationError
 }
@@ -1,6 +1,12 @@
 package moka
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
@@ -56,6 +62,45 @@ var _ = Describe("Moka", func() {
 		Expect(failHandlerCalled).To(BeFalse(), failHandlerMessage)
 	})
 
+	It("supports expecting multiple calls to the same method with different args", func() {
+		ExpectDouble(collaborator).To(ReceiveCallTo("Command").With("first").AndReturn("first result", nil))
+		ExpectDouble(collaborator).To(ReceiveCallTo("Command").With("second").AndReturn("second result", nil))
+
+		subject.DelegateCommand("second")
+		subject.DelegateCommand("first")
+		subject.DelegateCommand("second")
+
+		VerifyCalls(collaborator)
+		Expect(failHandlerCalled).To(BeFalse(), failHandlerMessage)
+	})
+
+	It("gives access to the calls matched by each expectation", func() {
+		first := ExpectDouble(collaborator).To(ReceiveCallTo("Command").With("first").AndReturn("first result", nil))
+		second := ExpectDouble(collaborator).To(ReceiveCallTo("Command").With("second").AndReturn("second result", nil))
+
+		subject.DelegateCommand("second")
+		subject.DelegateCommand("first")
+		subject.DelegateCommand("second")
+
+		Expect(first.Matches()).To(Equal(1))
+		Expect(second.MatchedCalls()).To(Equal([]Call{
+			{MethodName: "Command", Args: []interface{}{"second"}, InteractionIndex: 0},
+			{MethodName: "Command", Args: []interface{}{"second"}, InteractionIndex: 1},
+		}))
+		Expect(failHandlerCalled).To(BeFalse(), failHandlerMessage)
+	})
+
+	It("makes tests fail when only some of multiple expected calls to the same method happen", func() {
+		ExpectDouble(collaborator).To(ReceiveCallTo("Command").With("first").AndReturn("first result", nil))
+		ExpectDouble(collaborator).To(ReceiveCallTo("Command").With("second").AndReturn("second result", nil))
+
+		subject.DelegateCommand("first")
+
+		VerifyCalls(collaborator)
+		Expect(failHandlerCalled).To(BeTrue())
+		Expect(failHandlerMessage).To(Equal("Expected interaction: Command(\"second\")"))
+	})
+
 	It("supports allowing a method call on a double without specifying any args", func() {
 		AllowDouble(collaborator).To(ReceiveCallTo("Query").AndReturn("result"))
 
@@ -89,6 +134,17 @@ var _ = Describe("Moka", func() {
 		Expect(result).To(Equal("result"))
 	})
 
+	It("supports a custom behaviour for a method with variadic args", func() {
+		AllowDouble(collaborator).To(ReceiveCallTo("VariadicQuery").AndDo(func(args ...string) string {
+			return strings.Join(args, ",")
+		}))
+
+		result := subject.DelegateVariadicQuery("arg1", "arg2")
+
+		Expect(failHandlerCalled).To(BeFalse(), failHandlerMessage)
+		Expect(result).To(Equal("arg1,arg2"))
+	})
+
 	It("supports allowing a method call on a double with a custom behaviour", func() {
 		AllowDouble(collaborator).To(ReceiveCallTo("Query").AndDo(func(arg string) string {
 			if arg == "arg" {
@@ -105,6 +161,382 @@ var _ = Describe("Moka", func() {
 		Expect(failHandlerCalled).To(BeFalse(), failHandlerMessage)
 		Expect(result).To(Equal("result"))
 	})
+
+	It("supports allowing a method call on a double to panic", func() {
+		AllowDouble(collaborator).To(ReceiveCallTo("Query").With("arg").AndPanic("boom"))
+
+		Expect(func() { subject.DelegateQuery("arg") }).To(Panic())
+		Expect(failHandlerCalled).To(BeFalse(), failHandlerMessage)
+	})
+
+	It("supports allowing a method call on a double to return an error", func() {
+		AllowDouble(collaborator).To(ReceiveCallTo("Command").With("arg").AndReturnError(errors.New("failure")))
+
+		Expect(failHandlerCalled).To(BeFalse(), failHandlerMessage)
+
+		result, err := subject.DelegateCommand("arg")
+
+		Expect(failHandlerCalled).To(BeFalse(), failHandlerMessage)
+		Expect(result).To(Equal(""))
+		Expect(err).To(MatchError("failure"))
+	})
+
+	It("supports expecting a method call on a double to return an error", func() {
+		ExpectDouble(collaborator).To(ReceiveCallTo("Command").With("arg").AndReturnError(errors.New("failure")))
+
+		result, err := subject.DelegateCommand("arg")
+
+		Expect(failHandlerCalled).To(BeFalse(), failHandlerMessage)
+		Expect(result).To(Equal(""))
+		Expect(err).To(MatchError("failure"))
+	})
+
+	It("supports capturing the arguments of a method call on a double", func() {
+		captor := NewCaptor[func(string)]()
+		AllowDouble(collaborator).To(ReceiveCallTo("AsyncCommand").With("arg", captor))
+
+		var result string
+		subject.DelegateAsyncCommand("arg", func(r string) {
+			result = r
+		})
+
+		Expect(failHandlerCalled).To(BeFalse(), failHandlerMessage)
+		Expect(captor.All()).To(HaveLen(1))
+
+		captor.Last()("result")
+
+		Expect(result).To(Equal("result"))
+	})
+
+	It("supports setting the arguments of a method call on a double", func() {
+		AllowDouble(collaborator).To(ReceiveCallTo("Decode").AndSetArg(0, "result").AndReturn(nil))
+
+		var result string
+		err := subject.DelegateDecode(&result)
+
+		Expect(failHandlerCalled).To(BeFalse(), failHandlerMessage)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal("result"))
+	})
+
+	It("supports setting the arguments of a method call on a double that panics", func() {
+		AllowDouble(collaborator).To(ReceiveCallTo("Decode").AndSetArg(0, "partial").AndPanic("boom"))
+
+		var result string
+		Expect(func() { subject.DelegateDecode(&result) }).To(Panic())
+
+		Expect(failHandlerCalled).To(BeFalse(), failHandlerMessage)
+		Expect(result).To(Equal("partial"))
+	})
+
+	It("supports returning an argument of a method call on a double", func() {
+		AllowDouble(collaborator).To(ReceiveCallTo("Command").AndReturnArg(0))
+
+		result, err := subject.DelegateCommand("arg")
+
+		Expect(failHandlerCalled).To(BeFalse(), failHandlerMessage)
+		Expect(result).To(Equal("arg"))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("supports setting the arguments of a method call on a double returning computed values", func() {
+		AllowDouble(collaborator).To(ReceiveCallTo("Decode").AndSetArg(0, "result").AndReturnFunc(func(call Call) []interface{} {
+			return []interface{}{nil}
+		}))
+
+		var result string
+		err := subject.DelegateDecode(&result)
+
+		Expect(failHandlerCalled).To(BeFalse(), failHandlerMessage)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal("result"))
+	})
+
+	It("supports delaying a method call on a double returning an argument", func() {
+		clock := NewFakeClock()
+		RegisterDoublesClock(clock)
+		defer RegisterDoublesClock(nil)
+
+		AllowDouble(collaborator).To(ReceiveCallTo("Command").AndDelay(time.Second).AndReturnArg(0))
+
+		results := make(chan string, 1)
+		go func() {
+			result, _ := subject.DelegateCommand("arg")
+			results <- result
+		}()
+
+		clock.WaitForTimers(1)
+		Consistently(results).ShouldNot(Receive())
+
+		clock.Advance(time.Second)
+
+		Eventually(results).Should(Receive(Equal("arg")))
+		Expect(failHandlerCalled).To(BeFalse(), failHandlerMessage)
+	})
+
+	It("supports computing the return values of a method call on a double", func() {
+		AllowDouble(collaborator).To(ReceiveCallTo("Query").AndReturnFunc(func(call Call) []interface{} {
+			return []interface{}{fmt.Sprintf("%s-%d", call.Args[0], call.InteractionIndex)}
+		}))
+
+		Expect(subject.DelegateQuery("arg")).To(Equal("arg-0"))
+		Expect(subject.DelegateQuery("arg")).To(Equal("arg-1"))
+		Expect(failHandlerCalled).To(BeFalse(), failHandlerMessage)
+	})
+
+	It("supports delaying a method call on a double", func() {
+		clock := NewFakeClock()
+		RegisterDoublesClock(clock)
+		defer RegisterDoublesClock(nil)
+
+		AllowDouble(collaborator).To(ReceiveCallTo("Query").With("arg").AndDelay(time.Second).AndReturn("result"))
+
+		results := make(chan string, 1)
+		go func() {
+			results <- subject.DelegateQuery("arg")
+		}()
+
+		clock.WaitForTimers(1)
+		Consistently(results).ShouldNot(Receive())
+
+		clock.Advance(time.Second)
+
+		Eventually(results).Should(Receive(Equal("result")))
+		Expect(failHandlerCalled).To(BeFalse(), failHandlerMessage)
+	})
+
+	It("supports blocking a method call on a double until its context is done", func() {
+		AllowDouble(collaborator).To(ReceiveCallTo("Fetch").AndBlockUntilWithContext(nil).AndReturn("result", nil))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		result, err := subject.DelegateFetch(ctx, "key")
+
+		Expect(failHandlerCalled).To(BeFalse(), failHandlerMessage)
+		Expect(result).To(Equal(""))
+		Expect(err).To(Equal(context.DeadlineExceeded))
+	})
+
+	It("supports waiting for asynchronous method calls on a double", func() {
+		ExpectDouble(collaborator).To(ReceiveCallTo("CommandWithNoReturnValues").With("arg"))
+
+		go subject.DelegateCommandWithNoReturnValues("arg")
+
+		call := WaitForCall(collaborator, "CommandWithNoReturnValues", time.Second)
+		Expect(call.Args).To(Equal([]interface{}{"arg"}))
+
+		VerifyCallsWithin(collaborator, time.Second)
+		Expect(failHandlerCalled).To(BeFalse(), failHandlerMessage)
+	})
+
+	It("supports being notified of method calls on a double", func() {
+		AllowDouble(collaborator).To(ReceiveCallTo("Query").AndReturn("result"))
+
+		calls := make(chan Call, 1)
+		NotifyCalls(collaborator, calls)
+
+		go subject.DelegateQuery("arg")
+
+		Eventually(calls).Should(Receive(Equal(Call{MethodName: "Query", Args: []interface{}{"arg"}})))
+	})
+
+	It("supports expecting a method call not to happen on a double", func() {
+		AllowDouble(collaborator).To(ReceiveCallTo("Query").AndReturn("result"))
+		ExpectDouble(collaborator).NotTo(ReceiveCallTo("Query").With("forbidden"))
+
+		Expect(subject.DelegateQuery("allowed")).To(Equal("result"))
+		Expect(failHandlerCalled).To(BeFalse(), failHandlerMessage)
+
+		subject.DelegateQuery("forbidden")
+		Expect(failHandlerCalled).To(BeTrue())
+		Expect(failHandlerMessage).To(Equal("Unexpected interaction: Query(\"forbidden\") (expected not to receive Query(\"forbidden\"))"))
+
+		failHandlerCalled = false
+		VerifyCalls(collaborator)
+		Expect(failHandlerCalled).To(BeTrue())
+	})
+
+	It("makes tests fail when expecting a method call not to happen on a method that doesn't exist", func() {
+		ExpectDouble(collaborator).NotTo(ReceiveCallTo("Qeury"))
+
+		Expect(failHandlerCalled).To(BeTrue())
+		Expect(failHandlerMessage).To(Equal("Invalid interaction: type 'CollaboratorDouble' has no method 'Qeury'"))
+	})
+
+	It("supports verifying that no unexpected method calls happened on a double", func() {
+		AllowDouble(collaborator).To(ReceiveCallTo("Query").AndReturn("result"))
+		ExpectDouble(collaborator).To(ReceiveCallTo("CommandWithNoReturnValues").With("arg"))
+
+		subject.DelegateCommandWithNoReturnValues("arg")
+		VerifyNoMoreInteractions(collaborator)
+		Expect(failHandlerCalled).To(BeFalse(), failHandlerMessage)
+
+		subject.DelegateQuery("arg")
+		VerifyNoMoreInteractions(collaborator)
+		Expect(failHandlerCalled).To(BeTrue())
+		Expect(failHandlerMessage).To(Equal("Unverified interactions: Query(\"arg\")"))
+	})
+
+	It("supports reporting unused allowances on a double", func() {
+		ReportUnusedAllowances(collaborator)
+		AllowDouble(collaborator).To(ReceiveCallTo("Query").AndReturn("result"))
+
+		VerifyCalls(collaborator)
+
+		Expect(failHandlerCalled).To(BeTrue())
+		Expect(failHandlerMessage).To(Equal("Unused allowance: Query()"))
+	})
+
+	It("lets the most recently configured interaction win", func() {
+		AllowDouble(collaborator).To(ReceiveCallTo("Query").AndReturn("broad"))
+		AllowDouble(collaborator).To(ReceiveCallTo("Query").With("arg").AndReturn("specific"))
+
+		Expect(subject.DelegateQuery("arg")).To(Equal("specific"))
+		Expect(subject.DelegateQuery("other")).To(Equal("broad"))
+		Expect(failHandlerCalled).To(BeFalse(), failHandlerMessage)
+	})
+
+	It("lets an allowance shadow a previously configured expectation", func() {
+		ExpectDouble(collaborator).To(ReceiveCallTo("Query").With("arg").AndReturn("expected"))
+		AllowDouble(collaborator).To(ReceiveCallTo("Query").AndReturn("allowed"))
+
+		Expect(subject.DelegateQuery("arg")).To(Equal("allowed"))
+		Expect(failHandlerCalled).To(BeFalse(), failHandlerMessage)
+
+		VerifyCalls(collaborator)
+		Expect(failHandlerCalled).To(BeTrue())
+		Expect(failHandlerMessage).To(Equal("Expected interaction: Query(\"arg\")"))
+	})
+
+	It("supports removing the interactions configured on a double", func() {
+		AllowDouble(collaborator).To(ReceiveCallTo("Query").AndReturn("result"))
+		RemoveInteractions(collaborator, "Query")
+
+		subject.DelegateQuery("arg")
+		Expect(failHandlerCalled).To(BeTrue())
+
+		failHandlerCalled = false
+		AllowDouble(collaborator).To(ReceiveCallTo("Query").AndReturn("result"))
+		ResetDouble(collaborator)
+
+		subject.DelegateQuery("arg")
+		Expect(failHandlerCalled).To(BeTrue())
+	})
+
+	It("supports allowing a method call on a double by default", func() {
+		AllowDouble(collaborator).To(ReceiveCallTo("Query").With("arg").AndReturn("specific"))
+		AllowDouble(collaborator).ByDefault(ReceiveCallTo("Query").AndReturn("default"))
+
+		Expect(subject.DelegateQuery("arg")).To(Equal("specific"))
+		Expect(subject.DelegateQuery("other")).To(Equal("default"))
+		Expect(failHandlerCalled).To(BeFalse(), failHandlerMessage)
+	})
+
+	It("supports allowing a method call on a double a limited number of times", func() {
+		AllowDouble(collaborator).To(ReceiveCallTo("Query").AndReturn("default"))
+		AllowDouble(collaborator).To(ReceiveCallTo("Query").AndReturn("first").Once())
+		AllowDouble(collaborator).To(ReceiveCallTo("Command").AndReturn("retry", nil).Times(2))
+
+		Expect(subject.DelegateQuery("arg")).To(Equal("first"))
+		Expect(subject.DelegateQuery("arg")).To(Equal("default"))
+		Expect(subject.DelegateCommand("arg")).To(Equal("retry"))
+		Expect(subject.DelegateCommand("arg")).To(Equal("retry"))
+		Expect(failHandlerCalled).To(BeFalse(), failHandlerMessage)
+
+		subject.DelegateCommand("arg")
+		Expect(failHandlerCalled).To(BeTrue())
+	})
+
+	It("supports limiting any kind of interaction", func() {
+		AllowDouble(collaborator).To(ReceiveCallTo("Query").AndReturn("default"))
+		AllowDouble(collaborator).To(ReceiveCallTo("Query").AndPanic("boom").Once())
+
+		Expect(func() { subject.DelegateQuery("arg") }).To(Panic())
+		Expect(subject.DelegateQuery("arg")).To(Equal("default"))
+	})
+
+	It("supports expecting a method call on a double an exact number of times", func() {
+		ExpectDouble(collaborator).To(ReceiveCallTo("Query").AndReturn("result").Times(2))
+
+		subject.DelegateQuery("arg")
+		VerifyCalls(collaborator)
+		Expect(failHandlerCalled).To(BeTrue())
+		Expect(failHandlerMessage).To(Equal("Expected interaction: Query() 2 times (matched 1)"))
+
+		failHandlerCalled = false
+		subject.DelegateQuery("arg")
+		VerifyCalls(collaborator)
+		Expect(failHandlerCalled).To(BeFalse(), failHandlerMessage)
+	})
+
+	It("doesn't allow limiting an interaction to less than one call", func() {
+		AllowDouble(collaborator).To(ReceiveCallTo("Query").Times(0))
+		Expect(failHandlerCalled).To(BeTrue())
+		Expect(failHandlerMessage).To(Equal("Invalid interaction: the number of calls to 'Query' must be positive, 0 given"))
+
+		failHandlerCalled = false
+		ExpectDouble(collaborator).To(ReceiveCallTo("Command").AndReturnError(errors.New("failed")).Times(-1))
+		Expect(failHandlerCalled).To(BeTrue())
+		Expect(failHandlerMessage).To(Equal("Invalid interaction: the number of calls to 'Command' must be positive, -1 given"))
+	})
+
+	It("supports configuring interactions for a limited scope", func() {
+		AllowDouble(collaborator).To(ReceiveCallTo("Query").AndReturn("outer"))
+
+		WithInteractions(collaborator, func() {
+			AllowDouble(collaborator).To(ReceiveCallTo("Query").AndReturn("inner"))
+
+			Expect(subject.DelegateQuery("arg")).To(Equal("inner"))
+		})
+
+		Expect(subject.DelegateQuery("arg")).To(Equal("outer"))
+		Expect(failHandlerCalled).To(BeFalse(), failHandlerMessage)
+	})
+
+	It("supports scoping interactions to the state of a double", func() {
+		SetDoubleState(collaborator, "closed")
+		AllowDouble(collaborator).To(InState("closed").ReceiveCallTo("Command").With("open").AndReturn("opened", nil).TransitionTo("open"))
+		AllowDouble(collaborator).To(InState("open").ReceiveCallTo("Query").AndReturn("result"))
+		AllowDouble(collaborator).To(InState("open").ReceiveCallTo("Command").With("close").AndReturn("closed", nil).TransitionTo("closed"))
+
+		Expect(subject.DelegateCommand("open")).To(Equal("opened"))
+		Expect(subject.DelegateQuery("arg")).To(Equal("result"))
+		Expect(subject.DelegateCommand("close")).To(Equal("closed"))
+		Expect(failHandlerCalled).To(BeFalse(), failHandlerMessage)
+
+		subject.DelegateQuery("arg")
+		Expect(failHandlerCalled).To(BeTrue())
+		Expect(failHandlerMessage).To(Equal(
+			"Unexpected interaction: Query(\"arg\") (in state 'closed', allowed calls: Command(\"open\") in state 'closed' transitioning to 'open')",
+		))
+	})
+
+	It("supports scoping negative expectations to the state of a double", func() {
+		SetDoubleState(collaborator, "open")
+		AllowDouble(collaborator).To(ReceiveCallTo("Query").AndReturn("result"))
+		ExpectDouble(collaborator).NotTo(InState("closed").ReceiveCallTo("Query"))
+
+		Expect(subject.DelegateQuery("arg")).To(Equal("result"))
+		Expect(failHandlerCalled).To(BeFalse(), failHandlerMessage)
+
+		SetDoubleState(collaborator, "closed")
+		subject.DelegateQuery("arg")
+		Expect(failHandlerCalled).To(BeTrue())
+		Expect(failHandlerMessage).To(Equal("Unexpected interaction: Query(\"arg\") (expected not to receive Query() in state 'closed')"))
+	})
+
+	It("makes tests fail when a custom behaviour panics", func() {
+		AllowDouble(collaborator).To(ReceiveCallTo("Query").AndDo(func(arg string) string {
+			panic("boom")
+		}))
+
+		result := subject.DelegateQuery("arg")
+
+		Expect(failHandlerCalled).To(BeTrue())
+		Expect(failHandlerMessage).To(HavePrefix("Panic in interaction: Query(\"arg\") (matched by Query with body func(string) string)\nboom\n"))
+		Expect(result).To(Equal(""))
+	})
 })
 
 type Collaborator interface {
@@ -112,6 +544,9 @@ type Collaborator interface {
 	Command(string) (string, error)
 	CommandWithNoReturnValues(string)
 	VariadicQuery(...string) string
+	AsyncCommand(string, func(string))
+	Decode(interface{}) error
+	Fetch(context.Context, string) (string, error)
 }
 
 type CollaboratorDouble struct {
@@ -156,6 +591,32 @@ func (d CollaboratorDouble) VariadicQuery(args ...string) string {
 	return returnValues[0].(string)
 }
 
+func (d CollaboratorDouble) AsyncCommand(arg string, callback func(string)) {
+	d.Call("AsyncCommand", arg, callback)
+}
+
+func (d CollaboratorDouble) Decode(value interface{}) error {
+	returnValues, err := d.Call("Decode", value)
+	if err != nil {
+		return err
+	}
+
+	returnedError, _ := returnValues[0].(error)
+	return returnedError
+}
+
+func (d CollaboratorDouble) Fetch(ctx context.Context, key string) (string, error) {
+	returnValues, err := d.Call("Fetch", ctx, key)
+	if err != nil {
+		return "", err
+	}
+
+	returnedString, _ := returnValues[0].(string)
+	returnedError, _ := returnValues[1].(error)
+
+	return returnedString, returnedError
+}
+
 type Subject struct {
 	collaborator Collaborator
 }
@@ -179,3 +640,15 @@ func (s Subject) DelegateVariadicQuery(args ...string) string {
 func (s Subject) DelegateCommandWithNoReturnValues(arg string) {
 	s.collaborator.CommandWithNoReturnValues(arg)
 }
+
+func (s Subject) DelegateAsyncCommand(arg string, callback func(string)) {
+	s.collaborator.AsyncCommand(arg, callback)
+}
+
+func (s Subject) DelegateDecode(value interface{}) error {
+	return s.collaborator.Decode(value)
+}
+
+func (s Subject) DelegateFetch(ctx context.Context, key string) (string, error) {
+	return s.collaborator.Fetch(ctx, key)
+}
@@ -0,0 +1,77 @@
+package moka
+
+import (
+	"reflect"
+	"sync"
+)
+
+// Recorder is a double that delegates all calls to a real object and
+// records them, together with their return values, so that they can be saved
+// to a cassette and replayed later with `NewStrictDoubleFromCassette`.
+type Recorder struct {
+	*StrictDouble
+	real     reflect.Value
+	recorded []RecordedCall
+	mutex    sync.Mutex
+}
+
+// RecordedCall is a call recorded by a `Recorder`.
+type RecordedCall struct {
+	MethodName   string
+	Args         []interface{}
+	ReturnValues []interface{}
+}
+
+// NewRecorder instantiates a new `Recorder` delegating to the specified real
+// object, using the global fail handler.
+func NewRecorder(real interface{}) *Recorder {
+	double := NewStrictDoubleWithTypeOf(real)
+
+	return &Recorder{
+		StrictDouble: double,
+		real:         reflect.ValueOf(real),
+		recorded:     []RecordedCall{},
+	}
+}
+
+// Call invokes the method with the same name on the real object, records the
+// call and returns the values returned by the real object. If the real object
+// has no such method, or the arguments don't match its parameters, the call is
+// handled as an unexpected interaction.
+func (r *Recorder) Call(methodName string, args ...interface{}) ([]interface{}, error) {
+	method := methodValue(r.real, methodName)
+	if !method.IsValid() || method.Type().NumIn() != len(args) {
+		return r.StrictDouble.Call(methodName, args...)
+	}
+
+	argValues := interfacesToValues(args, method.Type())
+	for i, argValue := range argValues {
+		if !argValue.Type().AssignableTo(method.Type().In(i)) {
+			return r.StrictDouble.Call(methodName, args...)
+		}
+	}
+
+	r.StrictDouble.recordCall(methodName, args)
+
+	recordedCall := RecordedCall{MethodName: methodName, Args: args, ReturnValues: callValue(method, argValues)}
+
+	r.mutex.Lock()
+	r.recorded = append(r.recorded, recordedCall)
+	r.mutex.Unlock()
+
+	return recordedCall.ReturnValues, nil
+}
+
+// RecordedCalls returns all recorded calls, in the order they have been
+// received.
+func (r *Recorder) RecordedCalls() []RecordedCall {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return append([]RecordedCall{}, r.recorded...)
+}
+
+// Save writes all recorded calls to a cassette.
+func (r *Recorder) Save(cassette Cassette) error {
+	return cassette.write(r.real.Type(), r.RecordedCalls())
+}
@@ -0,0 +1,105 @@
+package moka
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type realCollaborator struct {
+	numberOfCommands int
+}
+
+func (c *realCollaborator) Query(arg string) string {
+	return strings.ToUpper(arg)
+}
+
+func (c *realCollaborator) Command(arg string) (string, error) {
+	c.numberOfCommands++
+	if arg == "" {
+		return "", errors.New("empty command")
+	}
+
+	return strings.Repeat(arg, c.numberOfCommands), nil
+}
+
+func (c *realCollaborator) CommandWithNoReturnValues(arg string) {}
+
+func (c *realCollaborator) VariadicQuery(args ...string) string {
+	return strings.Join(args, ",")
+}
+
+func (c *realCollaborator) AsyncCommand(arg string, callback func(string)) {
+	callback(arg)
+}
+
+func (c *realCollaborator) Decode(value interface{}) error {
+	return nil
+}
+
+func (c *realCollaborator) Fetch(ctx context.Context, key string) (string, error) {
+	return "value of " + key, nil
+}
+
+var _ = Describe("Recorder", func() {
+	var recorder *Recorder
+	var collaborator CollaboratorDouble
+
+	BeforeEach(func() {
+		resetTestFail()
+		RegisterDoublesFailHandler(testFailHandler)
+
+		recorder = NewRecorder(&realCollaborator{})
+		collaborator = CollaboratorDouble{Double: recorder}
+	})
+
+	It("delegates calls to the real object", func() {
+		Expect(collaborator.Query("arg")).To(Equal("ARG"))
+		Expect(collaborator.VariadicQuery("a", "b")).To(Equal("a,b"))
+
+		var callbackArg string
+		collaborator.AsyncCommand("arg", func(arg string) { callbackArg = arg })
+		Expect(callbackArg).To(Equal("arg"))
+
+		Expect(testFailHandlerInvoked).To(BeFalse(), testFailMessage)
+	})
+
+	It("records all calls and their return values", func() {
+		collaborator.Query("arg")
+		collaborator.Command("")
+
+		Expect(recorder.RecordedCalls()).To(Equal([]RecordedCall{
+			{MethodName: "Query", Args: []interface{}{"arg"}, ReturnValues: []interface{}{"ARG"}},
+			{MethodName: "Command", Args: []interface{}{""}, ReturnValues: []interface{}{"", errors.New("empty command")}},
+		}))
+	})
+
+	It("keeps a call log, like any other double", func() {
+		collaborator.Query("arg")
+
+		Expect(recorder.WaitForCall("Query", 0)).To(Equal(Call{MethodName: "Query", Args: []interface{}{"arg"}, Index: 0}))
+	})
+
+	Context("when the real object has no such method", func() {
+		It("handles the call as an unexpected interaction", func() {
+			_, err := recorder.Call("Unknown", "arg")
+
+			Expect(err).To(MatchError("Unexpected interaction: Unknown(\"arg\")"))
+			Expect(testFailHandlerInvoked).To(BeTrue())
+			Expect(recorder.RecordedCalls()).To(BeEmpty())
+		})
+	})
+
+	Context("when the arguments don't match the method", func() {
+		It("handles the call as an unexpected interaction", func() {
+			_, err := recorder.Call("Query", 42)
+
+			Expect(err).To(MatchError("Unexpected interaction: Query(42)"))
+			Expect(testFailHandlerInvoked).To(BeTrue())
+			Expect(recorder.RecordedCalls()).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,107 @@
+package moka
+
+import (
+	"fmt"
+	"reflect"
+)
+
+type returnArgInteraction struct {
+	methodName  string
+	args        []interface{}
+	index       int
+	returnTypes []reflect.Type
+}
+
+func newReturnArgInteraction(methodName string, args []interface{}, index int) *returnArgInteraction {
+	return &returnArgInteraction{methodName: methodName, args: args, index: index}
+}
+
+func (i *returnArgInteraction) MethodName() string {
+	return i.methodName
+}
+
+func (i *returnArgInteraction) literalArgs() ([]interface{}, bool) {
+	return i.args, i.args != nil
+}
+
+func (i *returnArgInteraction) Call(methodName string, args []interface{}) ([]interface{}, bool, error) {
+	if !callMatches(i.methodName, i.args, methodName, args) {
+		return nil, false, nil
+	}
+
+	if i.index < 0 || i.index >= len(args) {
+		return nil, true, &ArgumentError{
+			MethodName: methodName,
+			Args:       args,
+			Index:      i.index,
+			Action:     "return",
+			Reason:     fmt.Sprintf("only %d arguments received", len(args)),
+		}
+	}
+
+	if i.returnTypes == nil {
+		return []interface{}{args[i.index]}, true, nil
+	}
+
+	returnValues := zeroValues(i.returnTypes)
+	if args[i.index] != nil {
+		returnValues[0] = args[i.index]
+	}
+
+	return returnValues, true, nil
+}
+
+func (i *returnArgInteraction) Verify() error {
+	return nil
+}
+
+func (i *returnArgInteraction) String() string {
+	return formatMethodCall(i.methodName, i.args)
+}
+
+// CheckType also records the return types of the method, so that all return
+// values but the first one can be filled with zero values.
+func (i *returnArgInteraction) CheckType(t reflect.Type) error {
+	method, err := lookupMethod(t, i.methodName)
+	if err != nil {
+		return err
+	}
+
+	err = checkArgs(t, method, i.args)
+	if err != nil {
+		return err
+	}
+
+	argTypes := methodArgTypes(method)
+	if i.index < 0 || i.index >= len(argTypes) {
+		return newInvalidInteractionError(
+			method.Name,
+			"method '%s.%s' takes %d arguments, cannot return argument %d",
+			typeName(t),
+			method.Name,
+			len(argTypes),
+			i.index+1,
+		)
+	}
+
+	returnTypes := methodReturnTypes(method)
+	if len(returnTypes) == 0 {
+		return newInvalidInteractionError(method.Name, "method '%s.%s' doesn't return any value", typeName(t), method.Name)
+	}
+
+	argType := argTypes[i.index]
+	if !argType.AssignableTo(returnTypes[0]) {
+		return newInvalidInteractionError(
+			method.Name,
+			"type of return value 1 of method '%s.%s' is '%s', type of argument %d is '%s'",
+			typeName(t),
+			method.Name,
+			typeString(returnTypes[0]),
+			i.index+1,
+			typeString(argType),
+		)
+	}
+
+	i.returnTypes = returnTypes
+	return nil
+}
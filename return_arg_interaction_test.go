@@ -0,0 +1,85 @@
+package moka
+
+import (
+	"reflect"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("returnArgInteraction", func() {
+	var interaction Interaction
+
+	BeforeEach(func() {
+		interaction = newReturnArgInteraction("RepeatQuestion", nil, 0)
+	})
+
+	Describe("call", func() {
+		Context("when the type hasn't been checked", func() {
+			It("returns the argument only", func() {
+				returnValues, matched, err := interaction.Call("RepeatQuestion", []interface{}{"why?"})
+
+				Expect(returnValues).To(Equal([]interface{}{"why?"}))
+				Expect(matched).To(BeTrue())
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		Context("when the type has been checked", func() {
+			BeforeEach(func() {
+				Expect(interaction.CheckType(reflect.TypeOf(myDeepThought{}))).To(Succeed())
+			})
+
+			It("returns the argument and zero values for the other return values", func() {
+				returnValues, matched, err := interaction.Call("RepeatQuestion", []interface{}{"why?"})
+
+				Expect(returnValues).To(Equal([]interface{}{"why?", nil}))
+				Expect(matched).To(BeTrue())
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		Context("when the method name doesn't match", func() {
+			It("doesn't match", func() {
+				returnValues, matched, err := interaction.Call("UltimateQuestion", []interface{}{"why?"})
+
+				Expect(returnValues).To(BeNil())
+				Expect(matched).To(BeFalse())
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		Context("when the argument doesn't exist", func() {
+			BeforeEach(func() {
+				interaction = newReturnArgInteraction("RepeatQuestion", nil, 1)
+			})
+
+			It("returns an error", func() {
+				_, matched, err := interaction.Call("RepeatQuestion", []interface{}{"why?"})
+
+				Expect(matched).To(BeTrue())
+				Expect(err).To(MatchError("Cannot return argument 2 of RepeatQuestion(\"why?\"): only 1 arguments received"))
+			})
+		})
+	})
+
+	Describe("checkType", func() {
+		It("fails when the argument doesn't exist", func() {
+			Expect(newReturnArgInteraction("RepeatQuestion", nil, 1).CheckType(reflect.TypeOf(myDeepThought{}))).To(
+				MatchError("Invalid interaction: method 'myDeepThought.RepeatQuestion' takes 1 arguments, cannot return argument 2"),
+			)
+		})
+
+		It("fails when the method doesn't return anything", func() {
+			Expect(newReturnArgInteraction("UltimateAnswerInto", nil, 0).CheckType(reflect.TypeOf(myDeepThought{}))).To(
+				MatchError("Invalid interaction: type of return value 1 of method 'myDeepThought.UltimateAnswerInto' is 'error', type of argument 1 is '*int'"),
+			)
+		})
+
+		It("fails when the argument type doesn't match the return type", func() {
+			Expect(newReturnArgInteraction("UltimateQuestion", nil, 0).CheckType(reflect.TypeOf(myDeepThought{}))).To(
+				MatchError("Invalid interaction: type of return value 1 of method 'myDeepThought.UltimateQuestion' is 'int', type of argument 1 is 'string'"),
+			)
+		})
+	})
+})
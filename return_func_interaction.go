@@ -0,0 +1,60 @@
+package moka
+
+import (
+	"reflect"
+	"sync"
+)
+
+type returnFuncInteraction struct {
+	methodName    string
+	args          []interface{}
+	returnFunc    func(Call) []interface{}
+	numberOfCalls int
+	mutex         sync.Mutex
+}
+
+func newReturnFuncInteraction(methodName string, args []interface{}, returnFunc func(Call) []interface{}) *returnFuncInteraction {
+	return &returnFuncInteraction{methodName: methodName, args: args, returnFunc: returnFunc}
+}
+
+func (i *returnFuncInteraction) MethodName() string {
+	return i.methodName
+}
+
+func (i *returnFuncInteraction) literalArgs() ([]interface{}, bool) {
+	return i.args, i.args != nil
+}
+
+func (i *returnFuncInteraction) Call(methodName string, args []interface{}) ([]interface{}, bool, error) {
+	if !callMatches(i.methodName, i.args, methodName, args) {
+		return nil, false, nil
+	}
+
+	i.mutex.Lock()
+	index := i.numberOfCalls
+	i.numberOfCalls++
+	i.mutex.Unlock()
+
+	returnValues, err := callRecovering(i, methodName, args, func() []interface{} {
+		return i.returnFunc(Call{MethodName: methodName, Args: args, InteractionIndex: index})
+	})
+
+	return returnValues, true, err
+}
+
+func (i *returnFuncInteraction) Verify() error {
+	return nil
+}
+
+func (i *returnFuncInteraction) String() string {
+	return formatMethodCall(i.methodName, i.args)
+}
+
+func (i *returnFuncInteraction) CheckType(t reflect.Type) error {
+	method, err := lookupMethod(t, i.methodName)
+	if err != nil {
+		return err
+	}
+
+	return checkArgs(t, method, i.args)
+}
@@ -0,0 +1,67 @@
+package moka
+
+import (
+	"reflect"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("returnFuncInteraction", func() {
+	var receivedCalls []Call
+	var interaction Interaction
+
+	BeforeEach(func() {
+		receivedCalls = []Call{}
+		interaction = newReturnFuncInteraction("RepeatQuestion", nil, func(call Call) []interface{} {
+			receivedCalls = append(receivedCalls, call)
+			return []interface{}{call.Args[0].(string) + "?", nil}
+		})
+	})
+
+	Describe("call", func() {
+		It("returns the values returned by the function", func() {
+			returnValues, matched, err := interaction.Call("RepeatQuestion", []interface{}{"why"})
+
+			Expect(returnValues).To(Equal([]interface{}{"why?", nil}))
+			Expect(matched).To(BeTrue())
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("passes the method name, the args and the call index to the function", func() {
+			interaction.Call("RepeatQuestion", []interface{}{"why"})
+			interaction.Call("UltimateQuestion", []interface{}{"life", "universe", "everything"})
+			interaction.Call("RepeatQuestion", []interface{}{"how"})
+
+			Expect(receivedCalls).To(Equal([]Call{
+				{MethodName: "RepeatQuestion", Args: []interface{}{"why"}, InteractionIndex: 0},
+				{MethodName: "RepeatQuestion", Args: []interface{}{"how"}, InteractionIndex: 1},
+			}))
+		})
+
+		Context("when the function panics", func() {
+			BeforeEach(func() {
+				interaction = newReturnFuncInteraction("RepeatQuestion", nil, func(call Call) []interface{} {
+					panic("don't panic")
+				})
+			})
+
+			It("returns an error", func() {
+				returnValues, matched, err := interaction.Call("RepeatQuestion", []interface{}{"why"})
+
+				Expect(returnValues).To(BeNil())
+				Expect(matched).To(BeTrue())
+				Expect(err).To(MatchError(HavePrefix("Panic in interaction: RepeatQuestion(\"why\") (matched by RepeatQuestion())\ndon't panic\n")))
+			})
+		})
+	})
+
+	Describe("checkType", func() {
+		It("checks the method name and the arguments", func() {
+			Expect(interaction.CheckType(reflect.TypeOf(myDeepThought{}))).To(Succeed())
+			Expect(newReturnFuncInteraction("RepeatQuestion", []interface{}{42}, nil).CheckType(reflect.TypeOf(myDeepThought{}))).To(
+				MatchError("Invalid interaction: type of argument 1 of method 'myDeepThought.RepeatQuestion' is 'string', 'int' given"),
+			)
+		})
+	})
+})
@@ -0,0 +1,29 @@
+package moka
+
+import "sync"
+
+// Scope collects the interactions configured on a double while it is open.
+type Scope interface {
+	// Close verifies the expectations configured on the double since the
+	// scope has been opened, failing the test if any of them hasn't been
+	// met, then removes all interactions configured since. Closing a scope
+	// more than once has no effect.
+	Close()
+}
+
+type strictDoubleScope struct {
+	double   *StrictDouble
+	sequence int
+	once     sync.Once
+}
+
+func (s *strictDoubleScope) Close() {
+	s.once.Do(func() {
+		s.double.closeScope(s.sequence)
+	})
+}
+
+// nullScope is returned for doubles that don't support scopes.
+type nullScope struct{}
+
+func (s nullScope) Close() {}
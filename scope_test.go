@@ -0,0 +1,105 @@
+package moka
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Scope", func() {
+	var double *StrictDouble
+
+	BeforeEach(func() {
+		resetTestFail()
+		double = NewStrictDoubleWithInteractionValidatorAndFailHandler(NewNullInteractionValidator(), testFailHandler)
+		AllowDouble(double).To(ReceiveCallTo("Query").AndReturn("outer"))
+	})
+
+	It("removes all interactions configured while it was open", func() {
+		scope := OpenScope(double)
+		AllowDouble(double).To(ReceiveCallTo("Query").AndReturn("inner"))
+		AllowDouble(double).To(ReceiveCallTo("Command").AndReturn("inner"))
+		ExpectDouble(double).To(ReceiveCallTo("Ping"))
+		ExpectDouble(double).NotTo(ReceiveCallTo("Query").With("forbidden"))
+
+		Expect(double.Call("Query", "arg")).To(Equal([]interface{}{"inner"}))
+		double.Call("Ping")
+
+		scope.Close()
+
+		Expect(double.Call("Query", "forbidden")).To(Equal([]interface{}{"outer"}))
+		Expect(double.VerifyInteractions()).To(Succeed())
+		Expect(testFailHandlerInvoked).To(BeFalse(), testFailMessage)
+
+		_, err := double.Call("Command", "arg")
+		Expect(err).To(MatchError("Unexpected interaction: Command(\"arg\")"))
+	})
+
+	It("fails when an expectation configured while it was open hasn't been met", func() {
+		scope := OpenScope(double)
+		ExpectDouble(double).To(ReceiveCallTo("Ping"))
+
+		scope.Close()
+
+		Expect(testFailHandlerInvoked).To(BeTrue())
+		Expect(testFailMessage).To(Equal("Expected interaction: Ping()"))
+
+		By("removing the expectation anyway", func() {
+			resetTestFail()
+			Expect(double.VerifyInteractions()).To(Succeed())
+		})
+	})
+
+	It("doesn't verify the expectations configured before it was opened", func() {
+		ExpectDouble(double).To(ReceiveCallTo("Ping"))
+		scope := OpenScope(double)
+
+		scope.Close()
+
+		Expect(testFailHandlerInvoked).To(BeFalse(), testFailMessage)
+	})
+
+	It("keeps the interactions configured after it has been closed", func() {
+		scope := OpenScope(double)
+		scope.Close()
+		AllowDouble(double).To(ReceiveCallTo("Command").AndReturn("after"))
+		scope.Close()
+
+		Expect(double.Call("Command", "arg")).To(Equal([]interface{}{"after"}))
+	})
+
+	It("supports nested scopes", func() {
+		outerScope := OpenScope(double)
+		AllowDouble(double).To(ReceiveCallTo("Query").AndReturn("first"))
+		innerScope := OpenScope(double)
+		AllowDouble(double).To(ReceiveCallTo("Query").AndReturn("second"))
+
+		innerScope.Close()
+		Expect(double.Call("Query", "arg")).To(Equal([]interface{}{"first"}))
+
+		outerScope.Close()
+		Expect(double.Call("Query", "arg")).To(Equal([]interface{}{"outer"}))
+	})
+
+	Describe("WithInteractions", func() {
+		It("removes the interactions configured by the function", func() {
+			WithInteractions(double, func() {
+				AllowDouble(double).To(ReceiveCallTo("Query").AndReturn("inner"))
+
+				Expect(double.Call("Query", "arg")).To(Equal([]interface{}{"inner"}))
+			})
+
+			Expect(double.Call("Query", "arg")).To(Equal([]interface{}{"outer"}))
+		})
+
+		It("verifies the expectations configured by the function", func() {
+			WithInteractions(double, func() {
+				ExpectDouble(double).To(ReceiveCallTo("Ping").Times(2))
+
+				double.Call("Ping")
+			})
+
+			Expect(testFailHandlerInvoked).To(BeTrue())
+			Expect(testFailMessage).To(Equal("Expected interaction: Ping() 2 times (matched 1)"))
+		})
+	})
+})
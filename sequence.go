@@ -0,0 +1,178 @@
+package moka
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SequenceRecorder collects the calls received by all doubles, in the order
+// they are received, and renders them as sequence diagrams. Register it with
+// `RegisterDoublesSequenceRecorder` and reset it before each spec.
+type SequenceRecorder struct {
+	calls        []SequenceCall
+	participants map[*StrictDouble]string
+	names        map[string]int
+	mutex        sync.Mutex
+}
+
+// SequenceCall is a call collected by a `SequenceRecorder`.
+type SequenceCall struct {
+	// Participant is the name of the double receiving the call in the
+	// diagrams. Doubles with the same name are told apart by a number.
+	Participant string
+	MethodName  string
+	Args        []interface{}
+	Time        time.Time
+}
+
+var globalSequenceRecorder *SequenceRecorder
+
+// RegisterDoublesSequenceRecorder registers a recorder collecting the calls
+// received by all doubles from now on. Registering `nil` stops collecting
+// calls.
+func RegisterDoublesSequenceRecorder(recorder *SequenceRecorder) {
+	globalSequenceRecorder = recorder
+}
+
+// NewSequenceRecorder instantiates a new, empty `SequenceRecorder`.
+func NewSequenceRecorder() *SequenceRecorder {
+	return &SequenceRecorder{
+		calls:        []SequenceCall{},
+		participants: map[*StrictDouble]string{},
+		names:        map[string]int{},
+	}
+}
+
+func (r *SequenceRecorder) record(double *StrictDouble, call Call) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	participant, found := r.participants[double]
+	if !found {
+		participant = double.name
+		if participant == "" {
+			participant = "StrictDouble"
+		}
+
+		r.names[participant]++
+		if r.names[participant] > 1 {
+			participant = fmt.Sprintf("%s %d", participant, r.names[participant])
+		}
+
+		r.participants[double] = participant
+	}
+
+	r.calls = append(r.calls, SequenceCall{
+		Participant: participant,
+		MethodName:  call.MethodName,
+		Args:        call.Args,
+		Time:        time.Now(),
+	})
+}
+
+// Calls returns all collected calls, in the order they have been received.
+func (r *SequenceRecorder) Calls() []SequenceCall {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return append([]SequenceCall{}, r.calls...)
+}
+
+// Reset clears all collected calls and participants.
+func (r *SequenceRecorder) Reset() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.calls = []SequenceCall{}
+	r.participants = map[*StrictDouble]string{}
+	r.names = map[string]int{}
+}
+
+// Mermaid renders the collected calls as a Mermaid sequence diagram, with the
+// calls sent by the test to the doubles.
+func (r *SequenceRecorder) Mermaid() string {
+	var diagram strings.Builder
+	diagram.WriteString("sequenceDiagram\n")
+
+	r.render(
+		func(id string, participant string) {
+			fmt.Fprintf(&diagram, "    participant %s as %s\n", id, escapeMermaid(participant))
+		},
+		func(id string, message string) {
+			fmt.Fprintf(&diagram, "    Test->>%s: %s\n", id, escapeMermaid(message))
+		},
+	)
+
+	return diagram.String()
+}
+
+// PlantUML renders the collected calls as a PlantUML sequence diagram, with
+// the calls sent by the test to the doubles.
+func (r *SequenceRecorder) PlantUML() string {
+	var diagram strings.Builder
+	diagram.WriteString("@startuml\n")
+
+	r.render(
+		func(id string, participant string) {
+			fmt.Fprintf(&diagram, "participant %q as %s\n", participant, id)
+		},
+		func(id string, message string) {
+			fmt.Fprintf(&diagram, "Test -> %s: %s\n", id, strings.ReplaceAll(message, "\\", "\\\\"))
+		},
+	)
+
+	diagram.WriteString("@enduml\n")
+	return diagram.String()
+}
+
+// render declares the test and all participants, in order of appearance,
+// then renders each call. Participants are identified as D1, D2 and so on.
+func (r *SequenceRecorder) render(participant func(id string, name string), message func(id string, text string)) {
+	calls := r.Calls()
+
+	ids := map[string]string{}
+	participant("Test", "Test")
+	for _, call := range calls {
+		if _, found := ids[call.Participant]; !found {
+			ids[call.Participant] = fmt.Sprintf("D%d", len(ids)+1)
+			participant(ids[call.Participant], call.Participant)
+		}
+	}
+
+	for _, call := range calls {
+		message(ids[call.Participant], formatMethodCall(call.MethodName, call.Args))
+	}
+}
+
+// escapeMermaid replaces the characters that end or alter Mermaid statements
+// with their entity codes.
+func escapeMermaid(text string) string {
+	return strings.NewReplacer("#", "#35;", ";", "#59;").Replace(text)
+}
+
+// SaveMermaid writes the Mermaid diagram to a file, creating its directory if
+// needed.
+func (r *SequenceRecorder) SaveMermaid(path string) error {
+	return createFile(path, r.Mermaid())
+}
+
+// SavePlantUML writes the PlantUML diagram to a file, creating its directory
+// if needed.
+func (r *SequenceRecorder) SavePlantUML(path string) error {
+	return createFile(path, r.PlantUML())
+}
+
+// FailHandler wraps a fail handler, appending the Mermaid diagram of the
+// collected calls to all failure messages.
+func (r *SequenceRecorder) FailHandler(failHandler FailHandler) FailHandler {
+	return func(message string, callerSkip ...int) {
+		skip := 0
+		if len(callerSkip) > 0 {
+			skip = callerSkip[0]
+		}
+
+		failHandler(message+"\n\nSequence of calls:\n"+r.Mermaid(), skip+1)
+	}
+}
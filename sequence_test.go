@@ -0,0 +1,134 @@
+package moka
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SequenceRecorder", func() {
+	var recorder *SequenceRecorder
+	var collaborator CollaboratorDouble
+	var otherCollaborator CollaboratorDouble
+	var logger *StrictDouble
+
+	BeforeEach(func() {
+		resetTestFail()
+		RegisterDoublesFailHandler(testFailHandler)
+
+		recorder = NewSequenceRecorder()
+		RegisterDoublesSequenceRecorder(recorder)
+
+		collaborator = NewCollaboratorDouble()
+		otherCollaborator = NewCollaboratorDouble()
+		logger = NewStrictDoubleWithName("logger")
+
+		AllowDouble(collaborator).To(ReceiveCallTo("Query").AndReturn("result"))
+		AllowDouble(otherCollaborator).To(ReceiveCallTo("Query").AndReturn("result"))
+		AllowDouble(logger).To(ReceiveCallTo("Log"))
+	})
+
+	AfterEach(func() {
+		RegisterDoublesSequenceRecorder(nil)
+	})
+
+	It("collects the calls received by all doubles, in order", func() {
+		collaborator.Query("first")
+		logger.Call("Log", "second")
+		otherCollaborator.Query("third")
+
+		calls := recorder.Calls()
+		Expect(calls).To(HaveLen(3))
+		Expect(calls[0].Participant).To(Equal("moka.CollaboratorDouble"))
+		Expect(calls[0].MethodName).To(Equal("Query"))
+		Expect(calls[0].Args).To(Equal([]interface{}{"first"}))
+		Expect(calls[1].Participant).To(Equal("logger"))
+		Expect(calls[2].Participant).To(Equal("moka.CollaboratorDouble 2"))
+		Expect(calls[1].Time).NotTo(BeTemporally("<", calls[0].Time))
+		Expect(calls[2].Time).NotTo(BeTemporally("<", calls[1].Time))
+	})
+
+	It("collects unexpected calls", func() {
+		logger.Call("Flush")
+
+		Expect(recorder.Calls()).To(HaveLen(1))
+		Expect(recorder.Calls()[0].MethodName).To(Equal("Flush"))
+	})
+
+	It("clears the collected calls when reset", func() {
+		collaborator.Query("first")
+		recorder.Reset()
+		otherCollaborator.Query("second")
+
+		Expect(recorder.Calls()).To(HaveLen(1))
+		Expect(recorder.Calls()[0].Participant).To(Equal("moka.CollaboratorDouble"))
+	})
+
+	It("renders Mermaid sequence diagrams", func() {
+		collaborator.Query("first")
+		logger.Call("Log", "#1; done")
+		collaborator.Query("third")
+
+		Expect(recorder.Mermaid()).To(Equal(`sequenceDiagram
+    participant Test as Test
+    participant D1 as moka.CollaboratorDouble
+    participant D2 as logger
+    Test->>D1: Query("first")
+    Test->>D2: Log("#35;1#59; done")
+    Test->>D1: Query("third")
+`))
+	})
+
+	It("renders PlantUML sequence diagrams", func() {
+		collaborator.Query("first")
+		logger.Call("Log", "second")
+
+		Expect(recorder.PlantUML()).To(Equal(`@startuml
+participant "Test" as Test
+participant "moka.CollaboratorDouble" as D1
+participant "logger" as D2
+Test -> D1: Query("first")
+Test -> D2: Log("second")
+@enduml
+`))
+	})
+
+	It("saves the diagrams to files", func() {
+		dir, err := os.MkdirTemp("", "moka-sequence")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(dir)
+
+		collaborator.Query("first")
+
+		mermaidPath := filepath.Join(dir, "diagrams", "spec.mmd")
+		Expect(recorder.SaveMermaid(mermaidPath)).To(Succeed())
+		Expect(os.ReadFile(mermaidPath)).To(Equal([]byte(recorder.Mermaid())))
+
+		plantUMLPath := filepath.Join(dir, "diagrams", "spec.puml")
+		Expect(recorder.SavePlantUML(plantUMLPath)).To(Succeed())
+		Expect(os.ReadFile(plantUMLPath)).To(Equal([]byte(recorder.PlantUML())))
+	})
+
+	It("appends the Mermaid diagram to failure messages", func() {
+		double := NewStrictDoubleWithInteractionValidatorAndFailHandler(
+			NewNullInteractionValidator(),
+			recorder.FailHandler(testFailHandler),
+		)
+
+		collaborator.Query("first")
+		double.Call("Unexpected")
+
+		Expect(testFailMessage).To(Equal(`Unexpected interaction: Unexpected()
+
+Sequence of calls:
+sequenceDiagram
+    participant Test as Test
+    participant D1 as moka.CollaboratorDouble
+    participant D2 as StrictDouble
+    Test->>D1: Query("first")
+    Test->>D2: Unexpected()
+`))
+	})
+})
@@ -0,0 +1,215 @@
+package moka
+
+import (
+	"fmt"
+	"reflect"
+)
+
+type argSetter struct {
+	index int
+	value interface{}
+}
+
+// setArgInteraction wraps an interaction, writing values through some of the
+// arguments (pointers, slices or maps) every time the interaction matches,
+// including when it panics.
+type setArgInteraction struct {
+	methodName  string
+	interaction Interaction
+	argSetters  []argSetter
+}
+
+func newSetArgInteraction(methodName string, interaction Interaction, argSetters []argSetter) setArgInteraction {
+	return setArgInteraction{methodName: methodName, interaction: interaction, argSetters: argSetters}
+}
+
+func (i setArgInteraction) MethodName() string {
+	return i.methodName
+}
+
+func (i setArgInteraction) unwrap() Interaction {
+	return i.interaction
+}
+
+func (i setArgInteraction) Call(methodName string, args []interface{}) ([]interface{}, bool, error) {
+	returnValues, matches, err := i.interaction.Call(methodName, args)
+	_, isPanic := err.(interactionPanic)
+	if !matches || (err != nil && !isPanic) {
+		return returnValues, matches, err
+	}
+
+	for _, setter := range i.argSetters {
+		if setter.index >= len(args) {
+			return nil, true, &ArgumentError{
+				MethodName: methodName,
+				Args:       args,
+				Index:      setter.index,
+				Action:     "set",
+				Reason:     fmt.Sprintf("only %d arguments received", len(args)),
+			}
+		}
+
+		err := setArg(args[setter.index], setter.value)
+		if err != nil {
+			return nil, true, &ArgumentError{MethodName: methodName, Args: args, Index: setter.index, Action: "set", Reason: err.Error()}
+		}
+	}
+
+	return returnValues, true, err
+}
+
+func (i setArgInteraction) Verify() error {
+	return i.interaction.Verify()
+}
+
+func (i setArgInteraction) String() string {
+	return fmt.Sprint(i.interaction)
+}
+
+func (i setArgInteraction) CheckType(t reflect.Type) error {
+	err := i.interaction.CheckType(t)
+	if err != nil {
+		return err
+	}
+
+	method, err := lookupMethod(t, i.methodName)
+	if err != nil {
+		return err
+	}
+
+	argTypes := methodArgTypes(method)
+	for _, setter := range i.argSetters {
+		if setter.index < 0 || setter.index >= len(argTypes) {
+			return newInvalidInteractionError(
+				method.Name,
+				"method '%s.%s' takes %d arguments, cannot set argument %d",
+				typeName(t),
+				method.Name,
+				len(argTypes),
+				setter.index+1,
+			)
+		}
+
+		argType := argTypes[setter.index]
+		valueType := reflect.TypeOf(setter.value)
+		switch argType.Kind() {
+		case reflect.Interface:
+			continue
+		case reflect.Ptr:
+			if assignable(valueType, argType.Elem()) {
+				continue
+			}
+		case reflect.Slice, reflect.Map:
+			if valueType != nil && valueType.Kind() == argType.Kind() {
+				continue
+			}
+		default:
+			return newInvalidInteractionError(
+				method.Name,
+				"argument %d of method '%s.%s' is of type '%s', which cannot be set",
+				setter.index+1,
+				typeName(t),
+				method.Name,
+				typeString(argType),
+			)
+		}
+
+		return newInvalidInteractionError(
+			method.Name,
+			"cannot set argument %d of method '%s.%s' of type '%s' to a value of type '%s'",
+			setter.index+1,
+			typeName(t),
+			method.Name,
+			typeString(argType),
+			typeString(valueType),
+		)
+	}
+
+	return nil
+}
+
+// setArg writes `value` through `arg`. If `arg` is a pointer, the pointed
+// value is set. If `arg` is a slice, each of its elements is set to the
+// corresponding element of `value` (writing through the element if it's a
+// pointer, as with `Scan(dest ...interface{})`). If `arg` is a map, all
+// entries of `value` are copied into it.
+func setArg(arg interface{}, value interface{}) error {
+	argValue := reflect.ValueOf(arg)
+
+	switch argValue.Kind() {
+	case reflect.Ptr:
+		if argValue.IsNil() {
+			return fmt.Errorf("the argument is a nil pointer")
+		}
+
+		return assignValue(argValue.Elem(), value)
+	case reflect.Slice:
+		valueValue := reflect.ValueOf(value)
+		if valueValue.Kind() != reflect.Slice {
+			return fmt.Errorf("'%s' is not a slice", typeString(reflect.TypeOf(value)))
+		}
+
+		for j := 0; j < argValue.Len() && j < valueValue.Len(); j++ {
+			err := assignElement(argValue.Index(j), valueValue.Index(j).Interface())
+			if err != nil {
+				return fmt.Errorf("element %d: %s", j, err)
+			}
+		}
+
+		return nil
+	case reflect.Map:
+		valueValue := reflect.ValueOf(value)
+		if valueValue.Kind() != reflect.Map {
+			return fmt.Errorf("'%s' is not a map", typeString(reflect.TypeOf(value)))
+		}
+
+		if argValue.IsNil() {
+			return fmt.Errorf("the argument is a nil map")
+		}
+
+		iterator := valueValue.MapRange()
+		for iterator.Next() {
+			if !iterator.Key().Type().AssignableTo(argValue.Type().Key()) || !iterator.Value().Type().AssignableTo(argValue.Type().Elem()) {
+				return fmt.Errorf("'%s' is not assignable to '%s'", valueValue.Type(), argValue.Type())
+			}
+
+			argValue.SetMapIndex(iterator.Key(), iterator.Value())
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("'%s' is not a pointer, a slice or a map", typeString(reflect.TypeOf(arg)))
+}
+
+func assignElement(element reflect.Value, value interface{}) error {
+	target := element
+	if target.Kind() == reflect.Interface {
+		target = target.Elem()
+	}
+
+	if target.Kind() == reflect.Ptr && !target.IsNil() && assignable(reflect.TypeOf(value), target.Type().Elem()) {
+		return assignValue(target.Elem(), value)
+	}
+
+	return assignValue(element, value)
+}
+
+func assignValue(target reflect.Value, value interface{}) error {
+	if value == nil {
+		if !isNillable(target.Type()) {
+			return fmt.Errorf("nil is not assignable to '%s'", target.Type())
+		}
+
+		target.Set(reflect.Zero(target.Type()))
+		return nil
+	}
+
+	valueValue := reflect.ValueOf(value)
+	if !valueValue.Type().AssignableTo(target.Type()) {
+		return fmt.Errorf("'%s' is not assignable to '%s'", valueValue.Type(), target.Type())
+	}
+
+	target.Set(valueValue)
+	return nil
+}
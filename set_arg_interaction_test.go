@@ -0,0 +1,199 @@
+package moka
+
+import (
+	"errors"
+	"reflect"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("setArgInteraction", func() {
+	var wrappedInteraction *fakeInteraction
+	var argSetters []argSetter
+	var interaction Interaction
+
+	BeforeEach(func() {
+		wrappedInteraction = newFakeInteraction([]interface{}{nil}, true, errors.New("verify"), nil)
+		argSetters = []argSetter{{index: 0, value: 42}}
+	})
+
+	JustBeforeEach(func() {
+		interaction = newSetArgInteraction("UltimateAnswerInto", wrappedInteraction, argSetters)
+	})
+
+	Describe("call", func() {
+		var answer int
+
+		BeforeEach(func() {
+			answer = 0
+		})
+
+		Context("when the wrapped interaction matches", func() {
+			It("sets the argument and returns the wrapped interaction return values", func() {
+				returnValues, matched, err := interaction.Call("UltimateAnswerInto", []interface{}{&answer})
+
+				Expect(answer).To(Equal(42))
+				Expect(returnValues).To(Equal([]interface{}{nil}))
+				Expect(matched).To(BeTrue())
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		Context("when the wrapped interaction doesn't match", func() {
+			BeforeEach(func() {
+				wrappedInteraction = newFakeInteraction(nil, false, nil, nil)
+			})
+
+			It("doesn't set the argument", func() {
+				_, matched, err := interaction.Call("UltimateAnswerInto", []interface{}{&answer})
+
+				Expect(answer).To(Equal(0))
+				Expect(matched).To(BeFalse())
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		Context("when the wrapped interaction panics", func() {
+			BeforeEach(func() {
+				wrappedInteraction.callError = interactionPanic{value: "don't panic"}
+			})
+
+			It("sets the argument and returns the panic", func() {
+				_, matched, err := interaction.Call("UltimateAnswerInto", []interface{}{&answer})
+
+				Expect(answer).To(Equal(42))
+				Expect(matched).To(BeTrue())
+				Expect(err).To(Equal(interactionPanic{value: "don't panic"}))
+			})
+		})
+
+		Context("when the wrapped interaction fails", func() {
+			BeforeEach(func() {
+				wrappedInteraction.callError = errors.New("failed")
+			})
+
+			It("doesn't set the argument", func() {
+				_, matched, err := interaction.Call("UltimateAnswerInto", []interface{}{&answer})
+
+				Expect(answer).To(Equal(0))
+				Expect(matched).To(BeTrue())
+				Expect(err).To(MatchError("failed"))
+			})
+		})
+
+		Context("when the argument is a slice of pointers", func() {
+			BeforeEach(func() {
+				argSetters = []argSetter{{index: 0, value: []interface{}{42, "everything"}}}
+			})
+
+			It("writes through each pointer", func() {
+				var topic string
+				_, _, err := interaction.Call("Scan", []interface{}{[]interface{}{&answer, &topic}})
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(answer).To(Equal(42))
+				Expect(topic).To(Equal("everything"))
+			})
+		})
+
+		Context("when the argument is a map", func() {
+			BeforeEach(func() {
+				argSetters = []argSetter{{index: 0, value: map[string]int{"answer": 42}}}
+			})
+
+			It("copies all entries", func() {
+				answers := map[string]int{"question": 0}
+				_, _, err := interaction.Call("Fill", []interface{}{answers})
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(answers).To(Equal(map[string]int{"question": 0, "answer": 42}))
+			})
+		})
+
+		Context("when the argument can't be set", func() {
+			It("returns an error", func() {
+				returnValues, matched, err := interaction.Call("UltimateAnswerInto", []interface{}{answer})
+
+				Expect(returnValues).To(BeNil())
+				Expect(matched).To(BeTrue())
+				Expect(err).To(MatchError("Cannot set argument 1 of UltimateAnswerInto(0): 'int' is not a pointer, a slice or a map"))
+			})
+		})
+
+		Context("when the value is of the wrong type", func() {
+			BeforeEach(func() {
+				argSetters = []argSetter{{index: 0, value: "forty-two"}}
+			})
+
+			It("returns an error", func() {
+				_, _, err := interaction.Call("UltimateAnswerInto", []interface{}{&answer})
+
+				Expect(err).To(MatchError(HaveSuffix("'string' is not assignable to 'int'")))
+			})
+		})
+
+		Context("when the argument doesn't exist", func() {
+			BeforeEach(func() {
+				argSetters = []argSetter{{index: 1, value: 42}}
+			})
+
+			It("returns an error", func() {
+				_, _, err := interaction.Call("UltimateAnswerInto", []interface{}{&answer})
+
+				Expect(err).To(MatchError(HaveSuffix("only 1 arguments received")))
+			})
+		})
+	})
+
+	Describe("verify", func() {
+		It("delegates to the wrapped interaction", func() {
+			Expect(interaction.Verify()).To(MatchError("verify"))
+		})
+	})
+
+	Describe("checkType", func() {
+		var checkTypeError error
+
+		JustBeforeEach(func() {
+			checkTypeError = interaction.CheckType(reflect.TypeOf(myDeepThought{}))
+		})
+
+		Context("when the value can be set", func() {
+			It("succeeds", func() {
+				Expect(checkTypeError).NotTo(HaveOccurred())
+				Expect(wrappedInteraction.checkTypeCalled).To(BeTrue())
+			})
+		})
+
+		Context("when the wrapped interaction is invalid", func() {
+			BeforeEach(func() {
+				wrappedInteraction = newFakeInteraction(nil, true, nil, errors.New("invalid"))
+			})
+
+			It("fails", func() {
+				Expect(checkTypeError).To(MatchError("invalid"))
+			})
+		})
+
+		Context("when the argument doesn't exist", func() {
+			BeforeEach(func() {
+				argSetters = []argSetter{{index: 1, value: 42}}
+			})
+
+			It("fails", func() {
+				Expect(checkTypeError).To(MatchError("Invalid interaction: method 'myDeepThought.UltimateAnswerInto' takes 1 arguments, cannot set argument 2"))
+			})
+		})
+
+		Context("when the value is of the wrong type", func() {
+			BeforeEach(func() {
+				argSetters = []argSetter{{index: 0, value: "forty-two"}}
+			})
+
+			It("fails", func() {
+				Expect(checkTypeError).To(MatchError("Invalid interaction: cannot set argument 1 of method 'myDeepThought.UltimateAnswerInto' of type '*int' to a value of type 'string'"))
+			})
+		})
+	})
+})
@@ -0,0 +1,268 @@
+package moka
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// UpdateSnapshotsEnvVar is the environment variable that, when set to a
+// non-empty value, makes `MatchCallLogSnapshot` overwrite the snapshots
+// instead of comparing them.
+const UpdateSnapshotsEnvVar = "MOKA_UPDATE_SNAPSHOTS"
+
+// MatchCallLogSnapshot compares the log of the calls received by the double,
+// as returned by `Calls()`, with the snapshot stored in
+// `testdata/<name>.golden`, relative to the current directory. If the snapshot
+// doesn't exist yet, or the `UpdateSnapshotsEnvVar` environment variable is
+// set, the snapshot is written instead. It fails the test through the handler
+// registered with `RegisterDoublesFailHandler` if the call log doesn't match
+// the snapshot, and returns the error. The double must implement
+// `CallLogDouble`.
+func MatchCallLogSnapshot(double Double, name string) error {
+	callLogDouble, isSupported := lookupCapability[CallLogDouble](double)
+	if !isSupported {
+		err := fmt.Errorf("Double of type '%T' doesn't support MatchCallLogSnapshot", double)
+		globalFail(err.Error(), 2)
+		return err
+	}
+
+	err := matchSnapshot(name, formatCallLog(callLogDouble.Calls()))
+	if err != nil {
+		globalFail(err.Error(), 2)
+	}
+
+	return err
+}
+
+// formatCallLog renders a call log one call per line, in a format that
+// doesn't depend on memory addresses.
+func formatCallLog(calls []Call) string {
+	var callLog strings.Builder
+	for _, call := range calls {
+		args := []string{}
+		for _, arg := range call.Args {
+			args = append(args, formatSnapshotValue(reflect.ValueOf(arg), map[uintptr]bool{}))
+		}
+
+		fmt.Fprintf(&callLog, "%s(%s)\n", call.MethodName, strings.Join(args, ", "))
+	}
+
+	return callLog.String()
+}
+
+// formatSnapshotValue renders a value like `%#v`, replacing functions,
+// channels and contexts with their type and dereferencing pointers, including
+// the ones nested in other values. Pointers are tracked along the way, so that
+// cycles are rendered as their type.
+func formatSnapshotValue(value reflect.Value, pointers map[uintptr]bool) string {
+	formatted, _ := formatValue(value, snapshotFormatter{}, pointers, true)
+	return formatted
+}
+
+// valueFormatter customises the rendering of values by `formatValue`.
+type valueFormatter interface {
+	// formatSpecial renders the values that aren't rendered like `%#v`, and
+	// returns false for the others. A value is dynamic when its type isn't
+	// implied by where it appears, e.g. when it is held by an interface.
+	formatSpecial(value reflect.Value, dynamic bool) (string, bool, error)
+
+	// formatCycle renders a pointer that has already been dereferenced.
+	formatCycle(pointerType reflect.Type) (string, error)
+}
+
+type snapshotFormatter struct{}
+
+func (snapshotFormatter) formatSpecial(value reflect.Value, dynamic bool) (string, bool, error) {
+	valueType := value.Type()
+	if valueType.Kind() != reflect.Interface && valueType.Implements(contextType) {
+		return "context.Context", true, nil
+	}
+
+	switch valueType.Kind() {
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return valueType.String(), true, nil
+	}
+
+	return "", false, nil
+}
+
+func (snapshotFormatter) formatCycle(pointerType reflect.Type) (string, error) {
+	return fmt.Sprintf("(%s)(cycle)", pointerType), nil
+}
+
+// formatValue renders a value like `%#v`, dereferencing pointers, including
+// the ones nested in other values, and sorting map entries, so that the
+// result doesn't depend on memory addresses. Pointers are tracked along the
+// way to detect cycles.
+func formatValue(value reflect.Value, formatter valueFormatter, pointers map[uintptr]bool, dynamic bool) (string, error) {
+	if !value.IsValid() {
+		return "nil", nil
+	}
+
+	formatted, isSpecial, err := formatter.formatSpecial(value, dynamic)
+	if isSpecial || err != nil {
+		return formatted, err
+	}
+
+	valueType := value.Type()
+	switch valueType.Kind() {
+	case reflect.Interface:
+		if value.IsNil() {
+			return fmt.Sprintf("%s(nil)", valueType), nil
+		}
+
+		return formatValue(value.Elem(), formatter, pointers, true)
+	case reflect.Ptr:
+		if value.IsNil() {
+			return fmt.Sprintf("(%s)(nil)", valueType), nil
+		}
+		if pointers[value.Pointer()] {
+			return formatter.formatCycle(valueType)
+		}
+
+		pointers[value.Pointer()] = true
+		defer delete(pointers, value.Pointer())
+
+		elem, err := formatValue(value.Elem(), formatter, pointers, false)
+		return "&" + elem, err
+	case reflect.Struct:
+		fields := []string{}
+		for i := 0; i < value.NumField(); i++ {
+			field, err := formatValue(value.Field(i), formatter, pointers, false)
+			if err != nil {
+				return "", err
+			}
+			fields = append(fields, valueType.Field(i).Name+":"+field)
+		}
+
+		return fmt.Sprintf("%s{%s}", valueType, strings.Join(fields, ", ")), nil
+	case reflect.Slice, reflect.Array:
+		if valueType.Kind() == reflect.Slice && value.IsNil() {
+			return fmt.Sprintf("%s(nil)", valueType), nil
+		}
+
+		elements := []string{}
+		for i := 0; i < value.Len(); i++ {
+			element, err := formatValue(value.Index(i), formatter, pointers, false)
+			if err != nil {
+				return "", err
+			}
+			elements = append(elements, element)
+		}
+
+		return fmt.Sprintf("%s{%s}", valueType, strings.Join(elements, ", ")), nil
+	case reflect.Map:
+		if value.IsNil() {
+			return fmt.Sprintf("%s(nil)", valueType), nil
+		}
+
+		entries := []string{}
+		for _, key := range value.MapKeys() {
+			formattedKey, err := formatValue(key, formatter, pointers, false)
+			if err != nil {
+				return "", err
+			}
+			formattedValue, err := formatValue(value.MapIndex(key), formatter, pointers, false)
+			if err != nil {
+				return "", err
+			}
+			entries = append(entries, formattedKey+":"+formattedValue)
+		}
+		sort.Strings(entries)
+
+		return fmt.Sprintf("%s{%s}", valueType, strings.Join(entries, ", ")), nil
+	}
+
+	return fmt.Sprintf("%#v", value), nil
+}
+
+func matchSnapshot(name string, actual string) error {
+	path := filepath.Join("testdata", name+".golden")
+
+	expected, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) || os.Getenv(UpdateSnapshotsEnvVar) != "" {
+		return createFile(path, actual)
+	}
+	if err != nil {
+		return err
+	}
+
+	if string(expected) != actual {
+		return fmt.Errorf(
+			"Call log doesn't match snapshot '%s' (set %s=1 to update it):\n%s",
+			path,
+			UpdateSnapshotsEnvVar,
+			diffLines(string(expected), actual),
+		)
+	}
+
+	return nil
+}
+
+// createFile writes a file, creating its directory if needed.
+func createFile(path string, content string) error {
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, []byte(content), 0644)
+}
+
+// diffLines renders the differences between two texts line by line, prefixing
+// lines only in the expected text with "- ", lines only in the actual text with
+// "+ ", and common lines with two spaces, so that all lines stay aligned.
+func diffLines(expected string, actual string) string {
+	expectedLines := splitLines(expected)
+	actualLines := splitLines(actual)
+
+	// commonLengths[i][j] is the length of the longest common subsequence of
+	// expectedLines[i:] and actualLines[j:].
+	commonLengths := make([][]int, len(expectedLines)+1)
+	for i := range commonLengths {
+		commonLengths[i] = make([]int, len(actualLines)+1)
+	}
+	for i := len(expectedLines) - 1; i >= 0; i-- {
+		for j := len(actualLines) - 1; j >= 0; j-- {
+			if expectedLines[i] == actualLines[j] {
+				commonLengths[i][j] = commonLengths[i+1][j+1] + 1
+			} else if commonLengths[i+1][j] >= commonLengths[i][j+1] {
+				commonLengths[i][j] = commonLengths[i+1][j]
+			} else {
+				commonLengths[i][j] = commonLengths[i][j+1]
+			}
+		}
+	}
+
+	var diff strings.Builder
+	i, j := 0, 0
+	for i < len(expectedLines) || j < len(actualLines) {
+		switch {
+		case i < len(expectedLines) && j < len(actualLines) && expectedLines[i] == actualLines[j]:
+			fmt.Fprintf(&diff, "  %s\n", expectedLines[i])
+			i++
+			j++
+		case j == len(actualLines) || (i < len(expectedLines) && commonLengths[i+1][j] >= commonLengths[i][j+1]):
+			fmt.Fprintf(&diff, "- %s\n", expectedLines[i])
+			i++
+		default:
+			fmt.Fprintf(&diff, "+ %s\n", actualLines[j])
+			j++
+		}
+	}
+
+	return diff.String()
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return []string{}
+	}
+
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
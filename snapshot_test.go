@@ -0,0 +1,148 @@
+package moka
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type snapshotNode struct {
+	Value    *int
+	Callback func()
+	Labels   map[string]*int
+	Next     *snapshotNode
+}
+
+var _ = Describe("MatchCallLogSnapshot", func() {
+	var dir string
+	var workingDir string
+	var collaborator CollaboratorDouble
+
+	BeforeEach(func() {
+		resetTestFail()
+		RegisterDoublesFailHandler(testFailHandler)
+
+		var err error
+		workingDir, err = os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		dir, err = os.MkdirTemp("", "moka-snapshot")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir(dir)).To(Succeed())
+
+		collaborator = NewCollaboratorDouble()
+		AllowDouble(collaborator).To(ReceiveCallTo("Query").AndReturn("result"))
+		AllowDouble(collaborator).To(ReceiveCallTo("Command").AndReturn("result", nil))
+	})
+
+	AfterEach(func() {
+		os.Unsetenv(UpdateSnapshotsEnvVar)
+		Expect(os.Chdir(workingDir)).To(Succeed())
+		os.RemoveAll(dir)
+	})
+
+	readSnapshot := func(name string) string {
+		content, err := os.ReadFile(filepath.Join(dir, "testdata", name+".golden"))
+		Expect(err).NotTo(HaveOccurred())
+		return string(content)
+	}
+
+	It("writes missing snapshots", func() {
+		collaborator.Query("first")
+		collaborator.Command("second")
+
+		MatchCallLogSnapshot(collaborator, "protocol")
+
+		Expect(testFailHandlerInvoked).To(BeFalse())
+		Expect(readSnapshot("protocol")).To(Equal("Query(\"first\")\nCommand(\"second\")\n"))
+	})
+
+	It("succeeds when the call log matches the snapshot", func() {
+		collaborator.Query("first")
+		MatchCallLogSnapshot(collaborator, "protocol")
+
+		otherCollaborator := NewCollaboratorDouble()
+		AllowDouble(otherCollaborator).To(ReceiveCallTo("Query").AndReturn("result"))
+		otherCollaborator.Query("first")
+		MatchCallLogSnapshot(otherCollaborator, "protocol")
+
+		Expect(testFailHandlerInvoked).To(BeFalse())
+	})
+
+	It("fails with a diff when the call log doesn't match the snapshot", func() {
+		collaborator.Query("first")
+		collaborator.Command("second")
+		collaborator.Query("third")
+		MatchCallLogSnapshot(collaborator, "protocol")
+
+		otherCollaborator := NewCollaboratorDouble()
+		AllowDouble(otherCollaborator).To(ReceiveCallTo("Query").AndReturn("result"))
+		otherCollaborator.Query("first")
+		otherCollaborator.Query("third")
+		otherCollaborator.Query("fourth")
+		err := MatchCallLogSnapshot(otherCollaborator, "protocol")
+
+		Expect(err).To(MatchError(
+			"Call log doesn't match snapshot 'testdata/protocol.golden' (set MOKA_UPDATE_SNAPSHOTS=1 to update it):\n" +
+				"  Query(\"first\")\n" +
+				"- Command(\"second\")\n" +
+				"  Query(\"third\")\n" +
+				"+ Query(\"fourth\")\n",
+		))
+		Expect(testFailHandlerInvoked).To(BeTrue())
+		Expect(testFailMessage).To(Equal(err.Error()))
+	})
+
+	It("overwrites snapshots when the update environment variable is set", func() {
+		collaborator.Query("first")
+		MatchCallLogSnapshot(collaborator, "protocol")
+
+		os.Setenv(UpdateSnapshotsEnvVar, "1")
+		collaborator.Query("second")
+		MatchCallLogSnapshot(collaborator, "protocol")
+
+		Expect(testFailHandlerInvoked).To(BeFalse())
+		Expect(readSnapshot("protocol")).To(Equal("Query(\"first\")\nQuery(\"second\")\n"))
+	})
+
+	It("renders arguments independently of memory addresses", func() {
+		double := NewStrictDouble()
+		AllowDouble(double).To(ReceiveCallTo("Method"))
+		value := 42
+
+		double.Call("Method", &value, func() {}, make(chan string), context.Background(), nil)
+		MatchCallLogSnapshot(double, "arguments")
+
+		Expect(readSnapshot("arguments")).To(Equal("Method(&42, func(), chan string, context.Context, nil)\n"))
+	})
+
+	It("renders nested values independently of memory addresses", func() {
+		double := NewStrictDouble()
+		AllowDouble(double).To(ReceiveCallTo("Method"))
+		value := 42
+		node := &snapshotNode{Value: &value, Callback: func() {}, Labels: map[string]*int{"b": &value, "a": nil}}
+		node.Next = node
+
+		double.Call("Method", node, []*int{&value}, []interface{}{&value, nil})
+		MatchCallLogSnapshot(double, "nested")
+
+		Expect(readSnapshot("nested")).To(Equal(
+			"Method(" +
+				`&moka.snapshotNode{Value:&42, Callback:func(), Labels:map[string]*int{"a":(*int)(nil), "b":&42}, Next:(*moka.snapshotNode)(cycle)}, ` +
+				"[]*int{&42}, " +
+				"[]interface {}{&42, interface {}(nil)})\n",
+		))
+	})
+})
+
+var _ = Describe("diffLines", func() {
+	It("prefixes removed, added and common lines so that they stay aligned", func() {
+		Expect(diffLines("common\nremoved\n", "common\nadded\n")).To(Equal(
+			"  common\n" +
+				"- removed\n" +
+				"+ added\n",
+		))
+	})
+})
@@ -0,0 +1,167 @@
+package moka
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// doubleState holds the current state of a double. A nil `doubleState` is
+// always in the empty state.
+type doubleState struct {
+	name  string
+	mutex sync.Mutex
+}
+
+func (s *doubleState) get() string {
+	if s == nil {
+		return ""
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.name
+}
+
+func (s *doubleState) set(name string) {
+	if s == nil {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.name = name
+}
+
+// statefulInteraction is implemented by interactions depending on the state
+// of the double they are configured on.
+type statefulInteraction interface {
+	bindState(state *doubleState)
+}
+
+// bindState binds an interaction, and all the interactions it wraps, to the
+// state of a double.
+func bindState(interaction Interaction, state *doubleState) {
+	for {
+		if stateful, isStateful := interaction.(statefulInteraction); isStateful {
+			stateful.bindState(state)
+		}
+
+		wrapper, isWrapper := interaction.(wrappingInteraction)
+		if !isWrapper {
+			return
+		}
+		interaction = wrapper.unwrap()
+	}
+}
+
+// stateInteraction wraps an interaction, only matching calls when the double
+// is in a state, and moving the double to another state when it matches.
+type stateInteraction struct {
+	interaction Interaction
+	state       string
+	inState     bool
+	transition  string
+	doubleState *doubleState
+}
+
+func newStateInteraction(interaction Interaction, state string, inState bool, transition string) *stateInteraction {
+	return &stateInteraction{interaction: interaction, state: state, inState: inState, transition: transition}
+}
+
+func (i *stateInteraction) bindState(doubleState *doubleState) {
+	i.doubleState = doubleState
+}
+
+func (i *stateInteraction) MethodName() string {
+	return i.interaction.MethodName()
+}
+
+func (i *stateInteraction) unwrap() Interaction {
+	return i.interaction
+}
+
+// validIn returns true if the interaction can match calls received in a
+// state.
+func (i *stateInteraction) validIn(state string) bool {
+	return !i.inState || i.state == state
+}
+
+func (i *stateInteraction) Call(methodName string, args []interface{}) ([]interface{}, bool, error) {
+	if !i.validIn(i.doubleState.get()) {
+		return nil, false, nil
+	}
+
+	returnValues, matches, err := i.interaction.Call(methodName, args)
+	if matches && err == nil && i.transition != "" {
+		i.doubleState.set(i.transition)
+	}
+
+	return returnValues, matches, err
+}
+
+func (i *stateInteraction) Verify() error {
+	return i.interaction.Verify()
+}
+
+func (i *stateInteraction) String() string {
+	description := fmt.Sprint(i.interaction)
+	if i.inState {
+		description = fmt.Sprintf("%s in state '%s'", description, i.state)
+	}
+	if i.transition != "" {
+		description = fmt.Sprintf("%s transitioning to '%s'", description, i.transition)
+	}
+
+	return description
+}
+
+func (i *stateInteraction) CheckType(t reflect.Type) error {
+	return i.interaction.CheckType(t)
+}
+
+// anyStateScoped returns true if any of the interactions, or any of the
+// interactions they wrap, only matches calls in a state.
+func anyStateScoped(configuredInteractions []*configuredInteraction) bool {
+	for _, configuredInteraction := range configuredInteractions {
+		interaction := configuredInteraction.interaction
+		for {
+			if scoped, isScoped := interaction.(*stateInteraction); isScoped && scoped.inState {
+				return true
+			}
+
+			wrapper, isWrapper := interaction.(wrappingInteraction)
+			if !isWrapper {
+				break
+			}
+			interaction = wrapper.unwrap()
+		}
+	}
+
+	return false
+}
+
+// allowedInState returns the interactions that can match calls received in a
+// state, in configuration order.
+func allowedInState(configuredInteractions []*configuredInteraction, state string) []Interaction {
+	allowed := []Interaction{}
+	for _, configuredInteraction := range configuredInteractions {
+		interaction := configuredInteraction.interaction
+		for {
+			if scoped, isScoped := interaction.(*stateInteraction); isScoped && !scoped.validIn(state) {
+				break
+			}
+
+			wrapper, isWrapper := interaction.(wrappingInteraction)
+			if !isWrapper {
+				allowed = append(allowed, configuredInteraction.interaction)
+				break
+			}
+			interaction = wrapper.unwrap()
+		}
+	}
+
+	return allowed
+}
@@ -0,0 +1,152 @@
+package moka
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("stateInteraction", func() {
+	var fakeInteraction *fakeInteraction
+	var state *doubleState
+	var interaction Interaction
+
+	BeforeEach(func() {
+		fakeInteraction = newFakeInteraction([]interface{}{42}, true, nil, nil)
+		state = &doubleState{}
+	})
+
+	JustBeforeEach(func() {
+		bindState(interaction, state)
+	})
+
+	Context("when scoped to a state", func() {
+		BeforeEach(func() {
+			interaction = newStateInteraction(fakeInteraction, "open", true, "")
+		})
+
+		It("only matches calls received in that state", func() {
+			_, matched, _ := interaction.Call("UltimateQuestion", []interface{}{"life"})
+			Expect(matched).To(BeFalse())
+
+			state.set("open")
+			returnValues, matched, _ := interaction.Call("UltimateQuestion", []interface{}{"life"})
+			Expect(matched).To(BeTrue())
+			Expect(returnValues).To(Equal([]interface{}{42}))
+			Expect(state.get()).To(Equal("open"))
+		})
+
+		It("is described with its state", func() {
+			Expect(fmt.Sprint(interaction)).To(Equal("<the-interaction-string-representation> in state 'open'"))
+		})
+	})
+
+	Context("when transitioning to a state", func() {
+		BeforeEach(func() {
+			interaction = newStateInteraction(fakeInteraction, "closed", true, "open")
+			state.set("closed")
+		})
+
+		It("moves the double to that state when it matches", func() {
+			interaction.Call("UltimateQuestion", []interface{}{"life"})
+
+			Expect(state.get()).To(Equal("open"))
+		})
+
+		It("doesn't move the double when it doesn't match", func() {
+			fakeInteraction.matches = false
+			interaction.Call("UltimateQuestion", []interface{}{"life"})
+
+			Expect(state.get()).To(Equal("closed"))
+		})
+
+		It("doesn't move the double when the wrapped interaction fails", func() {
+			fakeInteraction.callError = errors.New("failed")
+			interaction.Call("UltimateQuestion", []interface{}{"life"})
+
+			Expect(state.get()).To(Equal("closed"))
+		})
+
+		It("is described with its state and transition", func() {
+			Expect(fmt.Sprint(interaction)).To(Equal("<the-interaction-string-representation> in state 'closed' transitioning to 'open'"))
+		})
+	})
+
+	Context("when wrapped in an expectation", func() {
+		BeforeEach(func() {
+			interaction = newExpectedInteraction(newStateInteraction(fakeInteraction, "open", true, ""))
+			state.set("open")
+		})
+
+		It("is bound to the state of the double", func() {
+			_, matched, _ := interaction.Call("UltimateQuestion", []interface{}{"life"})
+			Expect(matched).To(BeTrue())
+		})
+	})
+
+	It("delegates verification and type checking to the wrapped interaction", func() {
+		fakeInteraction = newFakeInteraction(nil, false, errors.New("unmet"), errors.New("invalid"))
+		interaction = newStateInteraction(fakeInteraction, "open", true, "")
+
+		Expect(interaction.Verify()).To(MatchError("unmet"))
+		Expect(interaction.CheckType(reflect.TypeOf(myDeepThought{}))).To(MatchError("invalid"))
+	})
+})
+
+var _ = Describe("StrictDouble states", func() {
+	var double *StrictDouble
+
+	BeforeEach(func() {
+		resetTestFail()
+		double = NewStrictDoubleWithInteractionValidatorAndFailHandler(NewNullInteractionValidator(), testFailHandler)
+	})
+
+	It("reports the current state and the allowed calls on unexpected calls", func() {
+		AllowDouble(double).To(ReceiveCallTo("Ping"))
+		AllowDouble(double).To(InState("open").ReceiveCallTo("Close").TransitionTo("closed"))
+		AllowDouble(double).To(InState("closed").ReceiveCallTo("Open").TransitionTo("open"))
+		SetDoubleState(double, "closed")
+
+		_, err := double.Call("Close")
+
+		Expect(err).To(MatchError("Unexpected interaction: Close() (in state 'closed', allowed calls: Ping(), Open() in state 'closed' transitioning to 'open')"))
+		Expect(err.(*UnexpectedCallError).State).To(Equal("closed"))
+		Expect(err.(*UnexpectedCallError).AllowedCalls).To(HaveLen(2))
+		Expect(testFailMessage).To(Equal(err.Error()))
+	})
+
+	It("reports the initial state and the allowed calls on unexpected calls before any transition", func() {
+		AllowDouble(double).To(ReceiveCallTo("Ping"))
+		AllowDouble(double).To(InState("open").ReceiveCallTo("Close").TransitionTo("closed"))
+		AllowDouble(double).To(ReceiveCallTo("Open").TransitionTo("open"))
+
+		_, err := double.Call("Close")
+
+		Expect(err).To(MatchError("Unexpected interaction: Close() (in the initial state, allowed calls: Ping(), Open() transitioning to 'open')"))
+		Expect(err.(*UnexpectedCallError).Stateful).To(BeTrue())
+		Expect(err.(*UnexpectedCallError).State).To(BeEmpty())
+		Expect(err.(*UnexpectedCallError).AllowedCalls).To(HaveLen(2))
+		Expect(testFailMessage).To(Equal(err.Error()))
+	})
+
+	It("reports when no calls are allowed in the current state", func() {
+		SetDoubleState(double, "closed")
+
+		_, err := double.Call("Close")
+
+		Expect(err).To(MatchError("Unexpected interaction: Close() (in state 'closed', no calls allowed)"))
+	})
+
+	It("clears the state when reset", func() {
+		AllowDouble(double).To(ReceiveCallTo("Open").TransitionTo("open"))
+		double.Call("Open")
+		ResetDouble(double)
+
+		_, err := double.Call("Open")
+
+		Expect(err).To(MatchError("Unexpected interaction: Open()"))
+	})
+})
@@ -4,6 +4,8 @@
 // the standard library.
 package moka
 
+import "time"
+
 // FailHandler is the type required for Moka fail handler functions. It matches
 // the type of the Ginkgo `Fail` function.
 type FailHandler func(message string, callerSkip ...int)
@@ -16,6 +18,17 @@ func RegisterDoublesFailHandler(failHandler FailHandler) {
 	globalFailHandler = failHandler
 }
 
+// globalFail fails the test through the handler registered with
+// `RegisterDoublesFailHandler`, or panics if there is none. The caller skip
+// is counted from `globalFail` itself.
+func globalFail(message string, callerSkip int) {
+	if globalFailHandler == nil {
+		panic(message)
+	}
+
+	globalFailHandler(message, callerSkip)
+}
+
 // AllowanceTarget wraps a Double to enable the configuration of allowed
 // interactions on it.
 type AllowanceTarget struct {
@@ -30,7 +43,17 @@ func AllowDouble(double Double) AllowanceTarget {
 // To configures the interaction built by the provided `InteractionBuilder` on
 // the wrapped `Double`.
 func (t AllowanceTarget) To(interactionBuilder InteractionBuilder) {
-	t.double.addInteraction(interactionBuilder.build())
+	t.double.AddInteraction(interactionBuilder.Build())
+}
+
+// ByDefault configures the interaction built by the provided
+// `InteractionBuilder` as a default on the wrapped `Double`: it will only
+// match calls that no other configured interaction matches, regardless of the
+// order in which they have been configured.
+func (t AllowanceTarget) ByDefault(interactionBuilder InteractionBuilder) {
+	if double, isSupported := capability[DefaultInteractionDouble](t.double, "default interactions"); isSupported {
+		double.AddDefaultInteraction(interactionBuilder.Build())
+	}
 }
 
 // ExpectationTarget wraps a Double to enable the configuration of expected
@@ -45,28 +68,134 @@ func ExpectDouble(double Double) ExpectationTarget {
 }
 
 // To configures the interaction built by the provided `InteractionBuilder` on
-// the wrapped `Double`.
-func (t ExpectationTarget) To(interactionBuilder InteractionBuilder) {
-	t.double.addInteraction(newExpectedInteraction(interactionBuilder.build()))
+// the wrapped `Double`, and returns the resulting `Expectation`, which gives
+// access to the calls it matches.
+func (t ExpectationTarget) To(interactionBuilder InteractionBuilder) Expectation {
+	expectation := newExpectedInteraction(interactionBuilder.Build())
+	t.double.AddInteraction(expectation)
+	return expectation
+}
+
+// NotTo configures the interaction built by the provided `InteractionBuilder`
+// as forbidden on the wrapped `Double`. Any matching call will make the test
+// fail immediately, regardless of any other configured interaction, and will
+// also be reported by `VerifyCalls`.
+func (t ExpectationTarget) NotTo(interactionBuilder InteractionBuilder) {
+	if double, isSupported := capability[NegativeInteractionDouble](t.double, "negative expectations"); isSupported {
+		double.AddNegativeInteraction(newNegativeExpectedInteraction(interactionBuilder.Build()))
+	}
 }
 
-// VerifyCalls verifies that all expected interactions on the wrapper `Double`
+// VerifyCalls verifies that all expected interactions on the wrapped `Double`
 // have actually happened.
 func VerifyCalls(double Double) {
-	double.verifyInteractions()
+	double.VerifyInteractions()
+}
+
+// VerifyNoMoreInteractions verifies that the wrapped `Double` hasn't received
+// any call other than the ones matched by expected interactions.
+func VerifyNoMoreInteractions(double Double) {
+	if exhaustiveDouble, isSupported := capability[ExhaustiveDouble](double, "VerifyNoMoreInteractions"); isSupported {
+		exhaustiveDouble.VerifyNoMoreInteractions()
+	}
+}
+
+// ReportUnusedAllowances makes `VerifyCalls` on the wrapped `Double` also
+// fail if any allowed interaction has never been matched. This helps finding
+// stale stubs.
+func ReportUnusedAllowances(double Double) {
+	if exhaustiveDouble, isSupported := capability[ExhaustiveDouble](double, "ReportUnusedAllowances"); isSupported {
+		exhaustiveDouble.ReportUnusedAllowances()
+	}
+}
+
+// RemoveInteractions removes all interactions configured on the wrapped
+// `Double` for the specified method, including expected and negative ones.
+func RemoveInteractions(double Double, methodName string) {
+	if resettableDouble, isSupported := capability[ResettableDouble](double, "RemoveInteractions"); isSupported {
+		resettableDouble.RemoveInteractions(methodName)
+	}
+}
+
+// ResetDouble removes all interactions configured on the wrapped `Double`,
+// clears the log of the calls it has received and its state.
+func ResetDouble(double Double) {
+	if resettableDouble, isSupported := capability[ResettableDouble](double, "ResetDouble"); isSupported {
+		resettableDouble.Reset()
+	}
+}
+
+// VerifyCallsWithin verifies that all expected interactions on the wrapped
+// `Double` happen within the specified timeout. Use it when the calls are
+// performed asynchronously, e.g. from a background goroutine.
+func VerifyCallsWithin(double Double, timeout time.Duration) {
+	if asyncDouble, isSupported := capability[AsyncDouble](double, "VerifyCallsWithin"); isSupported {
+		asyncDouble.VerifyInteractionsWithin(timeout)
+	}
+}
+
+// WaitForCall waits until the wrapped `Double` has received a call to the
+// specified method, and returns the last such call. If no call is received
+// within the specified timeout, the test will fail.
+func WaitForCall(double Double, methodName string, timeout time.Duration) Call {
+	asyncDouble, isSupported := capability[AsyncDouble](double, "WaitForCall")
+	if !isSupported {
+		return Call{}
+	}
+
+	return asyncDouble.WaitForCall(methodName, timeout)
+}
+
+// NotifyCalls causes all subsequent calls received by the wrapped `Double` to
+// be sent to the specified channel. Sends are non-blocking: the caller must
+// make sure the channel has enough buffer space to keep up with the calls.
+func NotifyCalls(double Double, channel chan<- Call) {
+	if asyncDouble, isSupported := capability[AsyncDouble](double, "NotifyCalls"); isSupported {
+		asyncDouble.NotifyCalls(channel)
+	}
+}
+
+// SetDoubleState moves the wrapped `Double` to a state, enabling the
+// interactions configured with `InState` for that state.
+func SetDoubleState(double Double, state string) {
+	if statefulDouble, isSupported := capability[StatefulDouble](double, "SetDoubleState"); isSupported {
+		statefulDouble.SetState(state)
+	}
+}
+
+// OpenScope opens a scope on the wrapped `Double`: closing the scope verifies
+// the expectations configured on the double in the meantime, then removes all
+// interactions configured in the meantime.
+func OpenScope(double Double) Scope {
+	resettableDouble, isSupported := capability[ResettableDouble](double, "OpenScope")
+	if !isSupported {
+		return nullScope{}
+	}
+
+	return resettableDouble.OpenScope()
+}
+
+// WithInteractions runs a function, then verifies the expectations and
+// removes all interactions it has configured on the wrapped `Double`.
+func WithInteractions(double Double, body func()) {
+	scope := OpenScope(double)
+	defer scope.Close()
+
+	body()
 }
 
 // InteractionBuilder provides a fluid interface to build interactions to
 // configure on a `Double`
 type InteractionBuilder interface {
-	build() interaction
+	Build() Interaction
 }
 
 // MethodInteractionBuilder allows to build interactions that are specific to a
 // method. It turns into more specific builders through the fluid interface
 // methods.
 type MethodInteractionBuilder struct {
-	methodName string
+	methodName  string
+	constraints interactionConstraints
 }
 
 // ReceiveCallTo allows to specify the method name of the interaction.
@@ -74,23 +203,132 @@ func ReceiveCallTo(methodName string) MethodInteractionBuilder {
 	return MethodInteractionBuilder{methodName: methodName}
 }
 
+// ReceiveCall allows to specify an interaction on a function double.
+func ReceiveCall() MethodInteractionBuilder {
+	return ReceiveCallTo(FuncMethodName)
+}
+
+// StateBuilder allows to build interactions that only match calls received
+// while the double is in a state.
+type StateBuilder struct {
+	state string
+}
+
+// InState allows to specify the state of the double in which the interaction
+// is valid. Doubles are moved to a state with `SetDoubleState`, or by
+// interactions configured with `TransitionTo`.
+func InState(state string) StateBuilder {
+	return StateBuilder{state: state}
+}
+
+// ReceiveCallTo allows to specify the method name of the interaction.
+func (b StateBuilder) ReceiveCallTo(methodName string) MethodInteractionBuilder {
+	return MethodInteractionBuilder{methodName: methodName, constraints: interactionConstraints{state: b.state, inState: true}}
+}
+
+// ReceiveCall allows to specify an interaction on a function double.
+func (b StateBuilder) ReceiveCall() MethodInteractionBuilder {
+	return b.ReceiveCallTo(FuncMethodName)
+}
+
 // With allows to specify the expected arguments of the interaction.
 func (b MethodInteractionBuilder) With(args ...interface{}) ArgsInteractionBuilder {
-	return ArgsInteractionBuilder{methodName: b.methodName, args: args}
+	return ArgsInteractionBuilder{methodName: b.methodName, args: args, constraints: b.constraints}
 }
 
 // AndReturn allows to specify the return value of the interaction.
 func (b MethodInteractionBuilder) AndReturn(returnValues ...interface{}) ArgsInteractionBuilder {
-	return ArgsInteractionBuilder{methodName: b.methodName, returnValues: returnValues}
+	return ArgsInteractionBuilder{methodName: b.methodName, returnValues: returnValues, constraints: b.constraints}
 }
 
 // AndDo allows to specify a custom body to be executed by the interaction.
 func (b MethodInteractionBuilder) AndDo(body interface{}) BodyInteractionBuilder {
-	return BodyInteractionBuilder{methodName: b.methodName, body: body}
+	return BodyInteractionBuilder{ConstrainedInteractionBuilder{
+		build:       func() Interaction { return newBodyInteraction(b.methodName, body) },
+		constraints: b.constraints,
+	}}
+}
+
+// AndSetArg allows to specify a value that will be written through the
+// argument at the specified (zero-based) index, which has to be a pointer, a
+// slice or a map, every time the interaction matches.
+func (b MethodInteractionBuilder) AndSetArg(index int, value interface{}) ArgsInteractionBuilder {
+	return ArgsInteractionBuilder{methodName: b.methodName, constraints: b.constraints}.AndSetArg(index, value)
+}
+
+// AndBlockUntil allows to specify a channel the interaction will block on
+// until it receives a value or is closed.
+func (b MethodInteractionBuilder) AndBlockUntil(channel <-chan struct{}) ArgsInteractionBuilder {
+	return ArgsInteractionBuilder{methodName: b.methodName, constraints: b.constraints}.AndBlockUntil(channel)
+}
+
+// AndBlockUntilWithContext is like `AndBlockUntil`, but it will also stop
+// blocking when the first `context.Context` argument is done, returning its
+// error. A nil channel will block until the context is done.
+func (b MethodInteractionBuilder) AndBlockUntilWithContext(channel <-chan struct{}) ArgsInteractionBuilder {
+	return ArgsInteractionBuilder{methodName: b.methodName, constraints: b.constraints}.AndBlockUntilWithContext(channel)
+}
+
+// AndDelay allows to specify a duration the interaction will wait for,
+// according to the clock registered with `RegisterDoublesClock`.
+func (b MethodInteractionBuilder) AndDelay(duration time.Duration) ArgsInteractionBuilder {
+	return ArgsInteractionBuilder{methodName: b.methodName, constraints: b.constraints}.AndDelay(duration)
+}
+
+// AndDelayWithContext is like `AndDelay`, but it will also stop waiting when
+// the first `context.Context` argument is done, returning its error.
+func (b MethodInteractionBuilder) AndDelayWithContext(duration time.Duration) ArgsInteractionBuilder {
+	return ArgsInteractionBuilder{methodName: b.methodName, constraints: b.constraints}.AndDelayWithContext(duration)
+}
+
+// AndReturnArg allows to specify that the interaction will return the
+// argument at the specified (zero-based) index. On typed doubles, the argument
+// will be the first return value, and all other return values will be zero
+// values of the corresponding return types of the method.
+func (b MethodInteractionBuilder) AndReturnArg(index int) ReturnArgInteractionBuilder {
+	return ArgsInteractionBuilder{methodName: b.methodName, constraints: b.constraints}.AndReturnArg(index)
+}
+
+// AndReturnFunc allows to specify a function computing the return values of
+// the interaction from the received `Call`.
+func (b MethodInteractionBuilder) AndReturnFunc(returnFunc func(call Call) []interface{}) ReturnFuncInteractionBuilder {
+	return ArgsInteractionBuilder{methodName: b.methodName, constraints: b.constraints}.AndReturnFunc(returnFunc)
+}
+
+// AndPanic allows to specify a value the interaction will panic with.
+func (b MethodInteractionBuilder) AndPanic(value interface{}) PanicInteractionBuilder {
+	return ArgsInteractionBuilder{methodName: b.methodName, constraints: b.constraints}.AndPanic(value)
+}
+
+// AndReturnError allows to specify an error the interaction will return. On
+// typed doubles, all other return values will be zero values of the
+// corresponding return types of the method. On untyped doubles, the error
+// will be the only return value.
+func (b MethodInteractionBuilder) AndReturnError(err error) ReturnErrorInteractionBuilder {
+	return ArgsInteractionBuilder{methodName: b.methodName, constraints: b.constraints}.AndReturnError(err)
+}
+
+// TransitionTo allows to specify a state the double will move to every time
+// the interaction matches.
+func (b MethodInteractionBuilder) TransitionTo(state string) ArgsInteractionBuilder {
+	return ArgsInteractionBuilder{methodName: b.methodName, constraints: b.constraints}.TransitionTo(state)
+}
+
+// Once allows to specify that the interaction will only match the first
+// matching call. Later calls fall through to other interactions.
+func (b MethodInteractionBuilder) Once() ArgsInteractionBuilder {
+	return b.Times(1)
 }
 
-func (b MethodInteractionBuilder) build() interaction {
-	return newArgsInteraction(b.methodName, nil, nil)
+// Times allows to specify that the interaction will only match the first
+// `times` matching calls. Later calls fall through to other interactions.
+// Expectations are only met once all of them have been matched.
+func (b MethodInteractionBuilder) Times(times int) ArgsInteractionBuilder {
+	return ArgsInteractionBuilder{methodName: b.methodName, constraints: b.constraints}.Times(times)
+}
+
+func (b MethodInteractionBuilder) Build() Interaction {
+	return b.constraints.wrap(newArgsInteraction(b.methodName, nil, nil))
 }
 
 // ArgsInteractionBuilder allows to build interactions that are defined by a
@@ -99,24 +337,243 @@ type ArgsInteractionBuilder struct {
 	methodName   string
 	args         []interface{}
 	returnValues []interface{}
+	wrappers     []interactionWrapper
+	constraints  interactionConstraints
+}
+
+// interactionConstraints restricts the interactions built by a builder to a
+// state of the double or to a number of matches, and makes them move the
+// double to another state when they match. The zero value doesn't restrict
+// interactions.
+type interactionConstraints struct {
+	state      string
+	inState    bool
+	transition string
+	limited    bool
+	times      int
+}
+
+func (c interactionConstraints) transitionTo(state string) interactionConstraints {
+	c.transition = state
+	return c
+}
+
+func (c interactionConstraints) limitTo(times int) interactionConstraints {
+	c.limited = true
+	c.times = times
+	return c
 }
 
+func (c interactionConstraints) wrap(interaction Interaction) Interaction {
+	if c.limited {
+		interaction = newLimitedInteraction(interaction, c.times)
+	}
+
+	if c.inState || c.transition != "" {
+		interaction = newStateInteraction(interaction, c.state, c.inState, c.transition)
+	}
+
+	return interaction
+}
+
+// interactionWrapper adds some behaviour to an interaction, e.g. setting
+// arguments or blocking.
+type interactionWrapper func(Interaction) Interaction
+
 // AndReturn allows to specify the return value of the interaction.
 func (b ArgsInteractionBuilder) AndReturn(returnValues ...interface{}) ArgsInteractionBuilder {
-	return ArgsInteractionBuilder{methodName: b.methodName, args: b.args, returnValues: returnValues}
+	b.returnValues = returnValues
+	return b
+}
+
+// AndSetArg allows to specify a value that will be written through the
+// argument at the specified (zero-based) index, which has to be a pointer, a
+// slice or a map, every time the interaction matches.
+func (b ArgsInteractionBuilder) AndSetArg(index int, value interface{}) ArgsInteractionBuilder {
+	return b.wrap(func(interaction Interaction) Interaction {
+		return newSetArgInteraction(b.methodName, interaction, []argSetter{{index: index, value: value}})
+	})
+}
+
+// AndBlockUntil allows to specify a channel the interaction will block on
+// until it receives a value or is closed.
+func (b ArgsInteractionBuilder) AndBlockUntil(channel <-chan struct{}) ArgsInteractionBuilder {
+	return b.wrap(func(interaction Interaction) Interaction {
+		return newBlockingInteraction(b.methodName, b.args, interaction, blockUntil(channel), false)
+	})
+}
+
+// AndBlockUntilWithContext is like `AndBlockUntil`, but it will also stop
+// blocking when the first `context.Context` argument is done, returning its
+// error. A nil channel will block until the context is done.
+func (b ArgsInteractionBuilder) AndBlockUntilWithContext(channel <-chan struct{}) ArgsInteractionBuilder {
+	return b.wrap(func(interaction Interaction) Interaction {
+		return newBlockingInteraction(b.methodName, b.args, interaction, blockUntil(channel), true)
+	})
+}
+
+// AndDelay allows to specify a duration the interaction will wait for,
+// according to the clock registered with `RegisterDoublesClock`.
+func (b ArgsInteractionBuilder) AndDelay(duration time.Duration) ArgsInteractionBuilder {
+	clock := globalClock
+	return b.wrap(func(interaction Interaction) Interaction {
+		return newBlockingInteraction(b.methodName, b.args, interaction, delay(clock, duration), false)
+	})
+}
+
+// AndDelayWithContext is like `AndDelay`, but it will also stop waiting when
+// the first `context.Context` argument is done, returning its error.
+func (b ArgsInteractionBuilder) AndDelayWithContext(duration time.Duration) ArgsInteractionBuilder {
+	clock := globalClock
+	return b.wrap(func(interaction Interaction) Interaction {
+		return newBlockingInteraction(b.methodName, b.args, interaction, delay(clock, duration), true)
+	})
+}
+
+func (b ArgsInteractionBuilder) wrap(wrapper interactionWrapper) ArgsInteractionBuilder {
+	b.wrappers = append(append([]interactionWrapper{}, b.wrappers...), wrapper)
+	return b
+}
+
+// AndReturnArg allows to specify that the interaction will return the
+// argument at the specified (zero-based) index. On typed doubles, the argument
+// will be the first return value, and all other return values will be zero
+// values of the corresponding return types of the method.
+func (b ArgsInteractionBuilder) AndReturnArg(index int) ReturnArgInteractionBuilder {
+	return ReturnArgInteractionBuilder{b.constrained(func() Interaction {
+		return newReturnArgInteraction(b.methodName, b.args, index)
+	})}
+}
+
+// AndReturnFunc allows to specify a function computing the return values of
+// the interaction from the received `Call`.
+func (b ArgsInteractionBuilder) AndReturnFunc(returnFunc func(call Call) []interface{}) ReturnFuncInteractionBuilder {
+	return ReturnFuncInteractionBuilder{b.constrained(func() Interaction {
+		return newReturnFuncInteraction(b.methodName, b.args, returnFunc)
+	})}
+}
+
+// AndPanic allows to specify a value the interaction will panic with.
+func (b ArgsInteractionBuilder) AndPanic(value interface{}) PanicInteractionBuilder {
+	return PanicInteractionBuilder{b.constrained(func() Interaction {
+		return newPanicInteraction(b.methodName, b.args, value)
+	})}
+}
+
+// AndReturnError allows to specify an error the interaction will return. On
+// typed doubles, all other return values will be zero values of the
+// corresponding return types of the method. On untyped doubles, the error
+// will be the only return value.
+func (b ArgsInteractionBuilder) AndReturnError(err error) ReturnErrorInteractionBuilder {
+	return ReturnErrorInteractionBuilder{b.constrained(func() Interaction {
+		return newReturnErrorInteraction(b.methodName, b.args, err)
+	})}
+}
+
+// TransitionTo allows to specify a state the double will move to every time
+// the interaction matches.
+func (b ArgsInteractionBuilder) TransitionTo(state string) ArgsInteractionBuilder {
+	b.constraints = b.constraints.transitionTo(state)
+	return b
+}
+
+// Once allows to specify that the interaction will only match the first
+// matching call. Later calls fall through to other interactions.
+func (b ArgsInteractionBuilder) Once() ArgsInteractionBuilder {
+	return b.Times(1)
+}
+
+// Times allows to specify that the interaction will only match the first
+// `times` matching calls. Later calls fall through to other interactions.
+// Expectations are only met once all of them have been matched.
+func (b ArgsInteractionBuilder) Times(times int) ArgsInteractionBuilder {
+	b.constraints = b.constraints.limitTo(times)
+	return b
+}
+
+func (b ArgsInteractionBuilder) Build() Interaction {
+	return b.constraints.wrap(wrapInteraction(newArgsInteraction(b.methodName, b.args, b.returnValues), b.wrappers))
+}
+
+// constrained returns a builder applying the wrappers and the constraints of
+// this builder to the interactions built by the specified function.
+func (b ArgsInteractionBuilder) constrained(build func() Interaction) ConstrainedInteractionBuilder {
+	return ConstrainedInteractionBuilder{
+		build:       func() Interaction { return wrapInteraction(build(), b.wrappers) },
+		constraints: b.constraints,
+	}
+}
+
+func wrapInteraction(interaction Interaction, wrappers []interactionWrapper) Interaction {
+	for _, wrapper := range wrappers {
+		interaction = wrapper(interaction)
+	}
+
+	return interaction
+}
+
+// ConstrainedInteractionBuilder allows to restrict the interactions built by
+// another builder to a number of matches, and to make them move the double to
+// another state when they match. It is embedded in the builders of
+// interactions that don't allow any further configuration.
+type ConstrainedInteractionBuilder struct {
+	build       func() Interaction
+	constraints interactionConstraints
 }
 
-func (b ArgsInteractionBuilder) build() interaction {
-	return newArgsInteraction(b.methodName, b.args, b.returnValues)
+// TransitionTo allows to specify a state the double will move to every time
+// the interaction matches.
+func (b ConstrainedInteractionBuilder) TransitionTo(state string) ConstrainedInteractionBuilder {
+	b.constraints = b.constraints.transitionTo(state)
+	return b
+}
+
+// Once allows to specify that the interaction will only match the first
+// matching call. Later calls fall through to other interactions.
+func (b ConstrainedInteractionBuilder) Once() ConstrainedInteractionBuilder {
+	return b.Times(1)
+}
+
+// Times allows to specify that the interaction will only match the first
+// `times` matching calls. Later calls fall through to other interactions.
+// Expectations are only met once all of them have been matched.
+func (b ConstrainedInteractionBuilder) Times(times int) ConstrainedInteractionBuilder {
+	b.constraints = b.constraints.limitTo(times)
+	return b
+}
+
+func (b ConstrainedInteractionBuilder) Build() Interaction {
+	return b.constraints.wrap(b.build())
+}
+
+// ReturnArgInteractionBuilder allows to build interactions that are defined
+// by a method name, a list of arguments and the index of the argument to
+// return
+type ReturnArgInteractionBuilder struct {
+	ConstrainedInteractionBuilder
+}
+
+// ReturnFuncInteractionBuilder allows to build interactions that are defined
+// by a method name, a list of arguments and a function computing the return
+// values
+type ReturnFuncInteractionBuilder struct {
+	ConstrainedInteractionBuilder
+}
+
+// PanicInteractionBuilder allows to build interactions that are defined by a
+// method name, a list of arguments and a value to panic with
+type PanicInteractionBuilder struct {
+	ConstrainedInteractionBuilder
+}
+
+// ReturnErrorInteractionBuilder allows to build interactions that are defined
+// by a method name, a list of arguments and an error to return
+type ReturnErrorInteractionBuilder struct {
+	ConstrainedInteractionBuilder
 }
 
 // BodyInteractionBuilder allows to build interactions that are defined by a
 // method name and a custom body
 type BodyInteractionBuilder struct {
-	methodName string
-	body       interface{}
-}
-
-func (b BodyInteractionBuilder) build() interaction {
-	return newBodyInteraction(b.methodName, b.body)
+	ConstrainedInteractionBuilder
 }